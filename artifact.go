@@ -15,6 +15,7 @@
 package gojenkins
 
 import (
+	"context"
 	"crypto/md5"
 	"errors"
 	"fmt"
@@ -34,62 +35,87 @@ type Artifact struct {
 
 // Get raw byte data of Artifact
 func (a Artifact) GetData() ([]byte, error) {
-	var data string
-	response, err := a.Jenkins.Requester.Get(a.Path, &data, nil)
+	return a.GetDataContext(context.Background())
+}
 
+func (a Artifact) GetDataContext(ctx context.Context) ([]byte, error) {
+	body, err := a.OpenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// Open the Artifact for reading, without loading it into memory. Optionally
+// only a range of bytes is read. The caller must close the reader.
+func (a Artifact) Open(rng ...ByteRange) (io.ReadCloser, error) {
+	return a.OpenContext(context.Background(), rng...)
+}
 
-	code := response.StatusCode
-	if code != 200 {
-		Error.Printf("Jenkins responded with StatusCode: %d", code)
-		return nil, errors.New("Could not get File Contents")
+func (a Artifact) OpenContext(ctx context.Context, rng ...ByteRange) (io.ReadCloser, error) {
+	response, err := a.Jenkins.Requester.GetStreamContext(ctx, a.Path, nil, rng...)
+	if err != nil {
+		return nil, err
 	}
-	return []byte(data), nil
+	return response.Body, nil
 }
 
 // Save artifact to a specific path, using your own filename.
 func (a Artifact) Save(path string) (bool, error) {
-	data, err := a.GetData()
+	return a.SaveContext(context.Background(), path)
+}
 
+func (a Artifact) SaveContext(ctx context.Context, path string) (bool, error) {
+	body, err := a.OpenContext(ctx)
 	if err != nil {
-		return false, errors.New("No Data received, not saving file.")
+		return false, err
 	}
+	defer body.Close()
 
 	if _, err = os.Stat(path); err == nil {
-		Warning.Println("Local Copy already exists, Overwriting...")
+		a.Jenkins.Requester.logger().Warn("Local Copy already exists, Overwriting...", "path", path)
 	}
 
-	err = ioutil.WriteFile(path, data, 0644)
-	a.validateDownload(path)
-
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return false, err
 	}
+	_, err = io.Copy(file, body)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return false, err
+	}
+	a.validateDownload(ctx, path)
 	return true, nil
 }
 
 // Save Artifact to directory using Artifact filename.
 func (a Artifact) SaveToDir(dir string) (bool, error) {
+	return a.SaveToDirContext(context.Background(), dir)
+}
+
+func (a Artifact) SaveToDirContext(ctx context.Context, dir string) (bool, error) {
 	if _, err := os.Stat(dir); err != nil {
-		Error.Printf("Can't Save Artifact. Directory %s does not exist...", dir)
+		a.Jenkins.Requester.logger().Error("Can't Save Artifact. Directory does not exist...", "dir", dir)
 		return false, errors.New(fmt.Sprintf("Can't Save Artifact. Directory %s does not exist...", dir))
 	}
-	saved, err := a.Save(path.Join(dir, a.FileName))
+	saved, err := a.SaveContext(ctx, path.Join(dir, a.FileName))
 	if err != nil {
-		return saved, nil
+		return saved, err
 	}
 	return saved, nil
 }
 
 // Compare Remote and local MD5
-func (a Artifact) validateDownload(path string) (bool, error) {
+func (a Artifact) validateDownload(ctx context.Context, path string) (bool, error) {
 	localHash := a.getMD5local(path)
 
 	fp := Fingerprint{Jenkins: a.Jenkins, Base: "/fingerprint/", Id: localHash, Raw: new(fingerPrintResponse)}
 
-	valid, err := fp.ValidateForBuild(a.FileName, a.Build)
+	valid, err := fp.ValidateForBuildContext(ctx, a.FileName, a.Build)
 
 	if err != nil {
 		return false, err
@@ -0,0 +1,141 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"net/http"
+)
+
+// Authenticator adds credentials to every request the Requester sends.
+// Implementations must only modify the request headers.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface,
+// e.g. to add a token that is refreshed from time to time.
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// Basic Authentication
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a *BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+func (a *BasicAuth) HeaderKeys() []string {
+	return []string{"Authorization"}
+}
+
+// Authentication with a user's API token, which Jenkins accepts in place of
+// the password. Unlike a password, it does not need a crumb for POST requests.
+type APITokenAuth struct {
+	Username string
+	Token    string
+}
+
+func (a *APITokenAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Token)
+	return nil
+}
+
+func (a *APITokenAuth) HeaderKeys() []string {
+	return []string{"Authorization"}
+}
+
+// Bearer token authentication, e.g. for an OAuth proxy in front of Jenkins.
+type BearerTokenAuth struct {
+	Token string
+}
+
+func (a *BearerTokenAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+func (a *BearerTokenAuth) HeaderKeys() []string {
+	return []string{"Authorization"}
+}
+
+// HeaderAuth sets arbitrary headers on every request, e.g. the headers a
+// reverse proxy expects from its clients.
+type HeaderAuth struct {
+	Headers http.Header
+}
+
+func (a *HeaderAuth) Authenticate(req *http.Request) error {
+	for k, v := range a.Headers {
+		req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+	return nil
+}
+
+func (a *HeaderAuth) HeaderKeys() []string {
+	keys := make([]string, 0, len(a.Headers))
+	for k := range a.Headers {
+		keys = append(keys, http.CanonicalHeaderKey(k))
+	}
+	return keys
+}
+
+// The Authenticator in use. Auth takes precedence over BasicAuth.
+func (r *Requester) authenticator() Authenticator {
+	if r.Auth != nil {
+		return r.Auth
+	}
+	if r.BasicAuth != nil {
+		return r.BasicAuth
+	}
+	return nil
+}
+
+// AuthHeaders is implemented by Authenticators that can name the headers they
+// set. The headers are removed when a redirect leaves the Jenkins origin, and
+// redacted in debug logs. For an Authenticator without it, all headers but
+// the ones the Requester sets itself are.
+type AuthHeaders interface {
+	HeaderKeys() []string
+}
+
+// Headers the Requester sets itself, which never carry credentials.
+var requesterHeaders = map[string]bool{"Accept": true, "Accept-Encoding": true, "Content-Length": true, "Content-Type": true, "User-Agent": true}
+
+// Names of the headers of h that auth may have set.
+func authHeaderKeys(auth Authenticator, h http.Header) []string {
+	if a, ok := auth.(AuthHeaders); ok {
+		return a.HeaderKeys()
+	}
+	var keys []string
+	for k := range h {
+		if !requesterHeaders[k] {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Credentials are only sent along if the redirect stays on the same host and
+// does not downgrade from https to http.
+func sameOrigin(req *http.Request, orig *http.Request) bool {
+	return req.URL.Host == orig.URL.Host && (req.URL.Scheme == orig.URL.Scheme || req.URL.Scheme == "https")
+}
@@ -0,0 +1,61 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthOnRedirect(t *testing.T) {
+	var seen []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = append(seen, "other:"+req.Header.Get("X-Proxy-Token")+req.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		seen = append(seen, req.URL.Path+":"+req.Header.Get("X-Proxy-Token"))
+		switch req.URL.Path {
+		case "/moved/":
+			http.Redirect(w, req, "/here/", http.StatusFound)
+		case "/away/":
+			http.Redirect(w, req, other.URL, http.StatusFound)
+		}
+	})
+	defer done()
+	r.Client.CheckRedirect = r.redirectPolicyFunc
+	r.Auth = &HeaderAuth{Headers: http.Header{"X-Proxy-Token": {"secret"}}}
+
+	_, err := r.Get("/moved", nil, nil)
+	assert.Nil(t, err)
+	_, err = r.Get("/away", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/moved/:secret", "/here/:secret", "/away/:secret", "other:"}, seen)
+}
+
+func TestAuthenticatorFuncOnRedirect(t *testing.T) {
+	var seen []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = append(seen, "other:"+req.Header.Get("X-Proxy-Token")+req.UserAgent())
+	}))
+	defer other.Close()
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, other.URL, http.StatusFound)
+	})
+	defer done()
+	r.Client.CheckRedirect = r.redirectPolicyFunc
+	r.UserAgent = "deploy-bot/1.0"
+	r.DebugHTTP = true
+	calls := 0
+	r.Auth = AuthenticatorFunc(func(req *http.Request) error {
+		calls++
+		req.Header.Set("X-Proxy-Token", "secret")
+		return nil
+	})
+
+	_, err := r.Get("/away", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other:deploy-bot/1.0"}, seen)
+	assert.Equal(t, 1, calls)
+}
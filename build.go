@@ -16,9 +16,11 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/url"
-	"regexp"
 	"strconv"
 	"time"
 )
@@ -32,8 +34,36 @@ type Build struct {
 }
 
 type parameter struct {
+	Class string
 	Name  string
 	Value string
+	// Set for file parameters only.
+	OriginalFileName string
+	// Whether Jenkins reported a value, it does not for passwords.
+	hasValue bool
+}
+
+// Values of boolean and other non-string parameters are kept as their JSON
+// text, e.g. "true".
+func (p *parameter) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Class            string          `json:"_class"`
+		Name             string          `json:"name"`
+		Value            json.RawMessage `json:"value"`
+		OriginalFileName string          `json:"originalFileName"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = parameter{Class: raw.Class, Name: raw.Name, OriginalFileName: raw.OriginalFileName}
+	if len(raw.Value) == 0 || string(raw.Value) == "null" {
+		return nil
+	}
+	p.hasValue = true
+	if err := json.Unmarshal(raw.Value, &p.Value); err != nil {
+		p.Value = string(raw.Value)
+	}
+	return nil
 }
 
 type branch struct {
@@ -70,36 +100,37 @@ type generalObj struct {
 	Subdir                  interface{}              `json:"subdir"`
 	TotalCount              int64
 	UrlName                 string
-}
-
-type TestResult struct {
-	Duration  int64 `json:"duration"`
-	Empty     bool  `json:"empty"`
-	FailCount int64 `json:"failCount"`
-	PassCount int64 `json:"passCount"`
-	SkipCount int64 `json:"skipCount"`
-	Suites    []struct {
-		Cases []struct {
-			Age             int64       `json:"age"`
-			ClassName       string      `json:"className"`
-			Duration        int64       `json:"duration"`
-			ErrorDetails    interface{} `json:"errorDetails"`
-			ErrorStackTrace interface{} `json:"errorStackTrace"`
-			FailedSince     int64       `json:"failedSince"`
-			Name            string      `json:"name"`
-			Skipped         bool        `json:"skipped"`
-			SkippedMessage  interface{} `json:"skippedMessage"`
-			Status          string      `json:"status"`
-			Stderr          interface{} `json:"stderr"`
-			Stdout          interface{} `json:"stdout"`
-		} `json:"cases"`
-		Duration  int64       `json:"duration"`
-		ID        interface{} `json:"id"`
-		Name      string      `json:"name"`
-		Stderr    interface{} `json:"stderr"`
-		Stdout    interface{} `json:"stdout"`
-		Timestamp interface{} `json:"timestamp"`
-	} `json:"suites"`
+	// Builds started with the build step of a Pipeline.
+	DownstreamBuilds []struct {
+		JobFullName string `json:"jobFullName"`
+		BuildNumber int64  `json:"buildNumber"`
+	} `json:"downstreamBuilds"`
+}
+
+// BuildChangeSet is the commits of a build in one repository.
+type BuildChangeSet struct {
+	Items []struct {
+		AffectedPaths []string `json:"affectedPaths"`
+		Author        struct {
+			AbsoluteUrl string `json:"absoluteUrl"`
+			FullName    string `json:"fullName"`
+		} `json:"author"`
+		Comment  string `json:"comment"`
+		CommitId string `json:"commitId"`
+		Date     string `json:"date"`
+		ID       string `json:"id"`
+		Msg      string `json:"msg"`
+		Paths    []struct {
+			EditType string `json:"editType"`
+			File     string `json:"file"`
+		} `json:"paths"`
+		Timestamp int64 `json:"timestamp"`
+	} `json:"items"`
+	Kind      string `json:"kind"`
+	Revisions []struct {
+		Module   string
+		Revision int
+	} `json:"revision"`
 }
 
 type BuildResponse struct {
@@ -109,46 +140,26 @@ type BuildResponse struct {
 		FileName     string `json:"fileName"`
 		RelativePath string `json:"relativePath"`
 	} `json:"artifacts"`
-	Building  bool   `json:"building"`
-	BuiltOn   string `json:"builtOn"`
-	ChangeSet struct {
-		Items []struct {
-			AffectedPaths []string `json:"affectedPaths"`
-			Author        struct {
-				AbsoluteUrl string `json:"absoluteUrl"`
-				FullName    string `json:"fullName"`
-			} `json:"author"`
-			Comment  string `json:"comment"`
-			CommitId string `json:"commitId"`
-			Date     string `json:"date"`
-			ID       string `json:"id"`
-			Msg      string `json:"msg"`
-			Paths    []struct {
-				EditType string `json:"editType"`
-				File     string `json:"file"`
-			} `json:"paths"`
-			Timestamp int64 `json:"timestamp"`
-		} `json:"items"`
-		Kind      string `json:"kind"`
-		Revisions []struct {
-			Module   string
-			Revision int
-		} `json:"revision"`
-	} `json:"changeSet"`
-	Culprits          []culprit   `json:"culprits"`
-	Description       interface{} `json:"description"`
-	Duration          int64       `json:"duration"`
-	EstimatedDuration int64       `json:"estimatedDuration"`
-	Executor          interface{} `json:"executor"`
-	FullDisplayName   string      `json:"fullDisplayName"`
-	ID                string      `json:"id"`
-	KeepLog           bool        `json:"keepLog"`
-	Number            int64       `json:"number"`
-	Result            string      `json:"result"`
-	Timestamp         int64       `json:"timestamp"`
-	URL               string      `json:"url"`
-	MavenArtifacts    interface{} `json:"mavenArtifacts"`
-	MavenVersionUsed  string      `json:"mavenVersionUsed"`
+	Building  bool           `json:"building"`
+	BuiltOn   string         `json:"builtOn"`
+	ChangeSet BuildChangeSet `json:"changeSet"`
+	// Pipeline builds list a changeset per repository.
+	ChangeSets        []BuildChangeSet `json:"changeSets"`
+	Culprits          []culprit        `json:"culprits"`
+	Description       interface{}      `json:"description"`
+	Duration          int64            `json:"duration"`
+	EstimatedDuration int64            `json:"estimatedDuration"`
+	Executor          interface{}      `json:"executor"`
+	FullDisplayName   string           `json:"fullDisplayName"`
+	ID                string           `json:"id"`
+	KeepLog           bool             `json:"keepLog"`
+	Number            int64            `json:"number"`
+	QueueID           int64            `json:"queueId"`
+	Result            string           `json:"result"`
+	Timestamp         int64            `json:"timestamp"`
+	URL               string           `json:"url"`
+	MavenArtifacts    interface{}      `json:"mavenArtifacts"`
+	MavenVersionUsed  string           `json:"mavenVersionUsed"`
 	Fingerprint       []fingerPrintResponse
 	Runs              []struct {
 		Number int64
@@ -194,8 +205,12 @@ func (b *Build) GetCulprits() []culprit {
 }
 
 func (b *Build) Stop() (bool, error) {
-	if b.IsRunning() {
-		response, err := b.Jenkins.Requester.Post(b.Base+"/stop", nil, nil, nil)
+	return b.StopContext(context.Background())
+}
+
+func (b *Build) StopContext(ctx context.Context) (bool, error) {
+	if b.IsRunningContext(ctx) {
+		response, err := b.Jenkins.Requester.PostContext(ctx, b.Base+"/stop", nil, nil, nil)
 		if err != nil {
 			return false, err
 		}
@@ -205,14 +220,42 @@ func (b *Build) Stop() (bool, error) {
 }
 
 func (b *Build) GetConsoleOutput() string {
+	return b.GetConsoleOutputContext(context.Background())
+}
+
+// GetConsoleOutputContext returns the whole console log, or "" if it cannot be
+// fetched. The error is logged, use ConsoleReader or StreamConsole to handle
+// it yourself.
+func (b *Build) GetConsoleOutputContext(ctx context.Context) string {
 	url := b.Base + "/consoleText"
 	var content string
-	b.Jenkins.Requester.GetXML(url, &content, nil)
+	if _, err := b.Jenkins.Requester.GetXMLContext(ctx, url, &content, nil); err != nil {
+		b.Jenkins.Requester.logger().Error("Can't get console output", "build", b.Base, "error", err)
+		return ""
+	}
 	return content
 }
 
+// Open the console log for reading, optionally only a range of bytes.
+// The caller must close the reader.
+func (b *Build) ConsoleReader(rng ...ByteRange) (io.ReadCloser, error) {
+	return b.ConsoleReaderContext(context.Background(), rng...)
+}
+
+func (b *Build) ConsoleReaderContext(ctx context.Context, rng ...ByteRange) (io.ReadCloser, error) {
+	response, err := b.Jenkins.Requester.GetStreamContext(ctx, b.Base+"/consoleText", nil, rng...)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
 func (b *Build) GetCauses() ([]map[string]interface{}, error) {
-	_, err := b.Poll()
+	return b.GetCausesContext(context.Background())
+}
+
+func (b *Build) GetCausesContext(ctx context.Context) ([]map[string]interface{}, error) {
+	_, err := b.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -234,11 +277,15 @@ func (b *Build) GetParameters() []parameter {
 }
 
 func (b *Build) GetInjectedEnvVars() (map[string]string, error) {
+	return b.GetInjectedEnvVarsContext(context.Background())
+}
+
+func (b *Build) GetInjectedEnvVarsContext(ctx context.Context) (map[string]string, error) {
 	var envVars struct {
 		EnvMap map[string]string `json:"envMap"`
 	}
 	endpoint := b.Base + "/injectedEnvVars"
-	_, err := b.Jenkins.Requester.GetJSON(endpoint, &envVars, nil)
+	_, err := b.Jenkins.Requester.GetJSONContext(ctx, endpoint, &envVars, nil)
 	if err != nil {
 		return envVars.EnvMap, err
 	}
@@ -246,41 +293,28 @@ func (b *Build) GetInjectedEnvVars() (map[string]string, error) {
 }
 
 func (b *Build) GetDownstreamBuilds() ([]*Build, error) {
-	result := make([]*Build, 0)
-	downstreamJobs, err := b.Job.GetDownstreamJobs()
-	if err != nil {
-		return nil, err
-	}
-	for _, job := range downstreamJobs {
-		allBuildIds, err := job.GetAllBuildIds()
-		if err != nil {
-			return nil, err
-		}
-		for _, buildId := range allBuildIds {
-			build, err := job.GetBuild(buildId.Number)
-			if err != nil {
-				return nil, err
-			}
-			upstreamBuild, _ := build.GetUpstreamBuild()
-			// cannot compare only id, it can be from different job
-			if b.GetUrl() == upstreamBuild.GetUrl() {
-				result = append(result, build)
-				break
-			}
-		}
-	}
-	return result, nil
+	return b.GetDownstreamBuildsContext(context.Background())
+}
+
+// GetDownstreamBuildsContext returns the builds this build started, see
+// FindDownstreamBuilds.
+func (b *Build) GetDownstreamBuildsContext(ctx context.Context) ([]*Build, error) {
+	return b.FindDownstreamBuildsContext(ctx, nil)
 }
 
 func (b *Build) GetDownstreamJobNames() []string {
+	return b.GetDownstreamJobNamesContext(context.Background())
+}
+
+func (b *Build) GetDownstreamJobNamesContext(ctx context.Context) []string {
 	result := make([]string, 0)
 	downstreamJobs := b.Job.GetDownstreamJobsMetadata()
-	fingerprints := b.GetAllFingerprints()
+	fingerprints := b.GetAllFingerprintsContext(ctx)
 	for _, fingerprint := range fingerprints {
 		for _, usage := range fingerprint.Raw.Usage {
 			for _, job := range downstreamJobs {
-				if job.Name == usage.Name {
-					result = append(result, job.Name)
+				if job.GetFullName() == usage.Name {
+					result = append(result, job.GetFullName())
 				}
 			}
 		}
@@ -289,7 +323,11 @@ func (b *Build) GetDownstreamJobNames() []string {
 }
 
 func (b *Build) GetAllFingerprints() []*Fingerprint {
-	b.Poll(3)
+	return b.GetAllFingerprintsContext(context.Background())
+}
+
+func (b *Build) GetAllFingerprintsContext(ctx context.Context) []*Fingerprint {
+	b.PollContext(ctx, 3)
 	result := make([]*Fingerprint, len(b.Raw.Fingerprint))
 	for i, f := range b.Raw.Fingerprint {
 		result[i] = &Fingerprint{Jenkins: b.Jenkins, Base: "/fingerprint/", Id: f.Hash, Raw: &f}
@@ -298,72 +336,87 @@ func (b *Build) GetAllFingerprints() []*Fingerprint {
 }
 
 func (b *Build) GetUpstreamJob() (*Job, error) {
-	causes, err := b.GetCauses()
+	return b.GetUpstreamJobContext(context.Background())
+}
+
+func (b *Build) GetUpstreamJobContext(ctx context.Context) (*Job, error) {
+	causes, err := b.GetTypedCausesContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if len(causes) > 0 {
-		if job, ok := causes[0]["upstreamProject"]; ok {
-			return b.Jenkins.GetJob(job.(string))
-		}
+	if upstream := upstreamCause(causes); upstream != nil {
+		return b.Jenkins.GetJobContext(ctx, upstream.UpstreamProject)
 	}
 	return nil, errors.New("Unable to get Upstream Job")
 }
 
+// GetUpstreamBuildNumber returns the number of the build that started this
+// one, or 0 if it was not started by another build.
 func (b *Build) GetUpstreamBuildNumber() (int64, error) {
-	causes, err := b.GetCauses()
+	return b.GetUpstreamBuildNumberContext(context.Background())
+}
+
+func (b *Build) GetUpstreamBuildNumberContext(ctx context.Context) (int64, error) {
+	causes, err := b.GetTypedCausesContext(ctx)
 	if err != nil {
 		return 0, err
 	}
-	if len(causes) > 0 {
-		if build, ok := causes[0]["upstreamBuild"]; ok {
-			switch t := build.(type) {
-			default:
-				return t.(int64), nil
-			case float64:
-				return int64(t), nil
-			}
-		}
+	if upstream := upstreamCause(causes); upstream != nil {
+		return upstream.UpstreamBuild, nil
 	}
 	return 0, nil
 }
 
 func (b *Build) GetUpstreamBuild() (*Build, error) {
-	job, err := b.GetUpstreamJob()
+	return b.GetUpstreamBuildContext(context.Background())
+}
+
+func (b *Build) GetUpstreamBuildContext(ctx context.Context) (*Build, error) {
+	causes, err := b.GetTypedCausesContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if job != nil {
-		buildNumber, err := b.GetUpstreamBuildNumber()
-		if err == nil {
-			return job.GetBuild(buildNumber)
-		}
+	upstream := upstreamCause(causes)
+	if upstream == nil {
+		return nil, errors.New("Build not found")
 	}
-	return nil, errors.New("Build not found")
+	job, err := b.Jenkins.GetJobContext(ctx, upstream.UpstreamProject)
+	if err != nil {
+		return nil, err
+	}
+	return job.GetBuildContext(ctx, upstream.UpstreamBuild)
 }
 
 func (b *Build) GetMatrixRuns() ([]*Build, error) {
-	_, err := b.Poll(0)
+	return b.GetMatrixRunsContext(context.Background())
+}
+
+func (b *Build) GetMatrixRunsContext(ctx context.Context) ([]*Build, error) {
+	_, err := b.PollContext(ctx, 0)
 	if err != nil {
 		return nil, err
 	}
 	runs := b.Raw.Runs
 	result := make([]*Build, len(b.Raw.Runs))
-	r, _ := regexp.Compile("job/(.*?)/(.*?)/(\\d+)/")
-
 	for i, run := range runs {
-		result[i] = &Build{Jenkins: b.Jenkins, Job: b.Job, Raw: new(BuildResponse), Depth: 1, Base: "/" + r.FindString(run.Url)}
-		result[i].Poll()
+		result[i] = &Build{Jenkins: b.Jenkins, Job: b.Job, Raw: new(BuildResponse), Depth: 1, Base: b.Jenkins.endpoint(run.Url)}
+		result[i].PollContext(ctx)
 	}
 	return result, nil
 }
 
-func (b *Build) GetResultSet() (*TestResult, error) {
+// GetResultSet returns the test report of the build. Optional parameters - a
+// *Tree selecting the fields to fetch, or the depth as int.
+func (b *Build) GetResultSet(options ...interface{}) (*TestResult, error) {
+	return b.GetResultSetContext(context.Background(), options...)
+}
+
+func (b *Build) GetResultSetContext(ctx context.Context, options ...interface{}) (*TestResult, error) {
 
 	url := b.Base + "/testReport"
 	var report TestResult
 
-	_, err := b.Jenkins.Requester.GetJSON(url, &report, nil)
+	_, err := b.Jenkins.Requester.GetJSONContext(ctx, url, &report, pollQuery(options))
 	if err != nil {
 		return nil, err
 	}
@@ -414,11 +467,21 @@ func (b *Build) GetRevisionBranch() string {
 }
 
 func (b *Build) IsGood() bool {
-	return (!b.IsRunning() && b.Raw.Result == STATUS_SUCCESS)
+	return b.IsGoodContext(context.Background())
+}
+
+func (b *Build) IsGoodContext(ctx context.Context) bool {
+	return (!b.IsRunningContext(ctx) && b.Raw.Result == STATUS_SUCCESS)
 }
 
+// IsRunning polls the build and reports whether it is still running. A failed
+// poll is reported as not running, use Wait to tell the two apart.
 func (b *Build) IsRunning() bool {
-	_, err := b.Poll()
+	return b.IsRunningContext(context.Background())
+}
+
+func (b *Build) IsRunningContext(ctx context.Context) bool {
+	_, err := b.PollContext(ctx)
 	if err != nil {
 		return false
 	}
@@ -426,22 +489,34 @@ func (b *Build) IsRunning() bool {
 }
 
 func (b *Build) SetDescription(description string) error {
+	return b.SetDescriptionContext(context.Background(), description)
+}
+
+func (b *Build) SetDescriptionContext(ctx context.Context, description string) error {
 	data := url.Values{}
 	data.Set("description", description)
-	if _, err := b.Jenkins.Requester.Post(b.Base+"/submitDescription", bytes.NewBufferString(data.Encode()), nil, nil); err != nil {
+	if _, err := b.Jenkins.Requester.PostContext(ctx, b.Base+"/submitDescription", bytes.NewBufferString(data.Encode()), nil, nil); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// Poll for current data. Optional parameter - depth.
+// Poll for current data. Optional parameters - depth, or a *Tree selecting
+// the fields to fetch.
 // More about depth here: https://wiki.jenkins-ci.org/display/JENKINS/Remote+access+API
 func (b *Build) Poll(options ...interface{}) (int, error) {
+	return b.PollContext(context.Background(), options...)
+}
+
+func (b *Build) PollContext(ctx context.Context, options ...interface{}) (int, error) {
 	depth := "-1"
+	var tree *Tree
 
 	for _, o := range options {
 		switch v := o.(type) {
+		case *Tree:
+			tree = v
 		case string:
 			depth = v
 		case int:
@@ -457,7 +532,10 @@ func (b *Build) Poll(options ...interface{}) (int, error) {
 	qr := map[string]string{
 		"depth": depth,
 	}
-	response, err := b.Jenkins.Requester.GetJSON(b.Base, b.Raw, qr)
+	if tree != nil {
+		qr["tree"] = tree.String()
+	}
+	response, err := b.Jenkins.Requester.GetJSONContext(ctx, b.Base, b.Raw, qr)
 	if err != nil {
 		return 0, err
 	}
@@ -19,9 +19,8 @@ func parseBuildHistory(d io.ReadCloser) []*History {
 		tt := z.Next()
 		switch tt {
 		case html.ErrorToken:
-			if z.Err() == io.EOF {
-				return builds
-			}
+			// io.EOF, or the body could not be read.
+			return builds
 		case html.SelfClosingTagToken:
 			tn, hasAttr := z.TagName()
 			// fmt.Println("START__", string(tn), hasAttr)
@@ -0,0 +1,287 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache keeps api/json responses, so that polling unchanged resources does
+// not download them again. It is keyed by URL, query included.
+//
+// Responses carrying an ETag or Last-Modified header are revalidated with a
+// conditional GET on every use, a 304 answer is served from the cache.
+// Other responses are served from the cache for TTL without asking Jenkins,
+// or not cached at all if TTL is zero.
+//
+// Every request other than GET drops the entries of the resource it acts on,
+// the entries below it, and those of its parents: a POST to /job/app/build
+// invalidates /job/app/..., as well as the api/json of /.
+//
+// Requests made with a context from WithoutCache skip fresh entries.
+//
+// Entries are not separated by credentials, do not share a store between
+// clients logged in as different users.
+type Cache struct {
+	Store CacheStore
+	TTL   time.Duration
+}
+
+// NewCache creates a cache on store. ttl applies to responses that cannot be
+// revalidated.
+func NewCache(store CacheStore, ttl time.Duration) *Cache {
+	return &Cache{Store: store, TTL: ttl}
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose requests are sent to Jenkins even if
+// the Cache holds a fresh entry, their responses are still stored. Use it
+// when polling for changes, so that they are seen before the TTL expires.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func noCacheFromContext(ctx context.Context) bool {
+	noCache, _ := ctx.Value(noCacheKey{}).(bool)
+	return noCache
+}
+
+// CacheEntry is a cached response.
+type CacheEntry struct {
+	Key        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Stored     time.Time
+}
+
+// CacheStore holds the entries of a Cache. Implementations must be safe for
+// concurrent use. Failures to store an entry are not reported, the response
+// is just fetched again next time.
+type CacheStore interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	// Remove all entries whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+func (e *CacheEntry) revalidate() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+func (e *CacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// The cached entry for key, and whether it can be used without asking Jenkins.
+func (c *Cache) lookup(key string) (entry *CacheEntry, fresh bool) {
+	entry, ok := c.Store.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if entry.revalidate() {
+		return entry, false
+	}
+	if c.TTL > 0 && time.Since(entry.Stored) < c.TTL {
+		return entry, true
+	}
+	return nil, false
+}
+
+// Read the body of a 200 response into the cache, and hand the response on
+// with the body buffered.
+func (c *Cache) store(key string, response *http.Response) error {
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	entry := &CacheEntry{Key: key, StatusCode: response.StatusCode, Header: response.Header.Clone(), Body: body, Stored: time.Now()}
+	entry.Header.Del("Set-Cookie")
+	if entry.revalidate() || c.TTL > 0 {
+		c.Store.Set(key, entry)
+	}
+	return nil
+}
+
+// Drop the entries a request to endpoint may have changed.
+func (c *Cache) invalidate(base string, endpoint string) {
+	resource := path.Dir(strings.TrimSuffix(endpoint, "/"))
+	if resource == "/" || resource == "." {
+		c.Store.DeletePrefix(base + "/")
+		return
+	}
+	c.Store.DeletePrefix(base + resource + "/")
+	for parent := path.Dir(resource); ; parent = path.Dir(parent) {
+		c.Store.DeletePrefix(base + strings.TrimSuffix(parent, "/") + "/api/")
+		if parent == "/" || parent == "." {
+			return
+		}
+	}
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+// NewMemoryStore returns a store that keeps up to maxEntries entries in
+// memory, evicting the least recently used one.
+func NewMemoryStore(maxEntries int) CacheStore {
+	return &memoryStore{maxEntries: maxEntries, entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+func (s *memoryStore) Get(key string) (*CacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(e)
+	return e.Value.(*CacheEntry), true
+}
+
+func (s *memoryStore) Set(key string, entry *CacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.Value = entry
+		s.lru.MoveToFront(e)
+		return
+	}
+	s.entries[key] = s.lru.PushFront(entry)
+	for s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*CacheEntry).Key)
+	}
+}
+
+func (s *memoryStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.lru.Remove(e)
+			delete(s.entries, key)
+		}
+	}
+}
+
+type diskStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewDiskStore returns a store that keeps one file per entry in dir, so the
+// cache survives restarts. The directory is created if needed.
+func NewDiskStore(dir string) (CacheStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &diskStore{dir: dir}, nil
+}
+
+func (s *diskStore) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *diskStore) read(file string) (*CacheEntry, bool) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	entry := new(CacheEntry)
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *diskStore) Get(key string) (*CacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.read(s.file(key))
+	if !ok || entry.Key != key {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *diskStore) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Write to a temporary file first, so readers never see half an entry.
+	tmp, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.file(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (s *diskStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, _ := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	for _, file := range files {
+		if entry, ok := s.read(file); !ok || strings.HasPrefix(entry.Key, prefix) {
+			os.Remove(file)
+		}
+	}
+}
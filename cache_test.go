@@ -0,0 +1,59 @@
+package gojenkins
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	hits := map[string]int{}
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		hits[req.Method+" "+req.URL.Path]++
+		switch req.URL.Path {
+		case "/job/a/api/json":
+			w.Header().Set("ETag", `"v1"`)
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte(`{"name":"a"}`))
+		case "/queue/api/json":
+			w.Write([]byte(`{}`))
+		}
+	})
+	defer done()
+	dir := t.TempDir()
+	for _, store := range []func() CacheStore{
+		func() CacheStore { return NewMemoryStore(10) },
+		func() CacheStore { s, _ := NewDiskStore(dir); return s },
+	} {
+		hits = map[string]int{}
+		r.Cache = NewCache(store(), time.Minute)
+
+		for i := 0; i < 3; i++ {
+			job := new(JobResponse)
+			response, err := r.GetJSON("/job/a", job, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, "a", job.Name)
+			_, err = r.GetJSON("/queue", new(queueResponse), nil)
+			assert.Nil(t, err)
+		}
+		// Revalidated every time, while the TTL saves the queue requests.
+		assert.Equal(t, 3, hits["GET /job/a/api/json"])
+		assert.Equal(t, 1, hits["GET /queue/api/json"])
+
+		r.Cache.Store.Set(r.Base+"/api/json", &CacheEntry{Key: r.Base + "/api/json", Header: http.Header{}, Stored: time.Now()})
+		_, err := r.Post("/job/a/disable", nil, nil, nil)
+		assert.Nil(t, err)
+		_, ok := r.Cache.Store.Get(r.Base + "/job/a/api/json")
+		assert.False(t, ok)
+		_, ok = r.Cache.Store.Get(r.Base + "/api/json")
+		assert.False(t, ok)
+		_, ok = r.Cache.Store.Get(r.Base + "/queue/api/json")
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Kind of a build cause.
+type CauseKind string
+
+const (
+	CauseUser           CauseKind = "user"
+	CauseTimer          CauseKind = "timer"
+	CauseSCM            CauseKind = "scm"
+	CauseUpstream       CauseKind = "upstream"
+	CauseRemote         CauseKind = "remote"
+	CauseReplay         CauseKind = "replay"
+	CauseBranchIndexing CauseKind = "branchIndexing"
+	CauseOther          CauseKind = "other"
+)
+
+var causeKinds = map[string]CauseKind{
+	"hudson.model.Cause$UserIdCause":                                        CauseUser,
+	"hudson.model.Cause$UserCause":                                          CauseUser,
+	"hudson.triggers.TimerTrigger$TimerTriggerCause":                        CauseTimer,
+	"hudson.triggers.SCMTrigger$SCMTriggerCause":                            CauseSCM,
+	"hudson.plugins.git.GitStatus$CommitHookCause":                          CauseSCM,
+	"com.cloudbees.jenkins.GitHubPushCause":                                 CauseSCM,
+	"jenkins.branch.BranchEventCause":                                       CauseSCM,
+	"hudson.model.Cause$UpstreamCause":                                      CauseUpstream,
+	"org.jenkinsci.plugins.workflow.support.steps.build.BuildUpstreamCause": CauseUpstream,
+	"hudson.model.Cause$RemoteCause":                                        CauseRemote,
+	"org.jenkinsci.plugins.workflow.cps.replay.ReplayCause":                 CauseReplay,
+	"jenkins.branch.BranchIndexingCause":                                    CauseBranchIndexing,
+}
+
+// Cause is why a build was started. Which fields are set depends on the
+// Kind.
+type Cause struct {
+	Class            string `json:"_class"`
+	ShortDescription string `json:"shortDescription"`
+	// Started by a user.
+	UserID   string `json:"userId"`
+	UserName string `json:"userName"`
+	// Started by another build, UpstreamProject is the full name of its job.
+	UpstreamProject string `json:"upstreamProject"`
+	UpstreamBuild   int64  `json:"upstreamBuild"`
+	UpstreamURL     string `json:"upstreamUrl"`
+	// Started remotely with a build token.
+	Addr string `json:"addr"`
+	Note string `json:"note"`
+}
+
+func (c *Cause) Kind() CauseKind {
+	if kind, ok := causeKinds[c.Class]; ok {
+		return kind
+	}
+	return CauseOther
+}
+
+// CauseLink is a build in a cause chain, with the causes it was started by.
+type CauseLink struct {
+	Job    string  `json:"job"` // full name
+	Number int64   `json:"number"`
+	Causes []Cause `json:"causes"`
+}
+
+// The most builds CauseChain follows, in case of a cycle.
+const maxCauseChain = 100
+
+// GetTypedCauses returns the causes of the build, like GetCauses, but typed.
+func (b *Build) GetTypedCauses() ([]Cause, error) {
+	return b.GetTypedCausesContext(context.Background())
+}
+
+func (b *Build) GetTypedCausesContext(ctx context.Context) ([]Cause, error) {
+	if _, err := b.PollContext(ctx); err != nil {
+		return nil, err
+	}
+	return buildCauses(b.Raw.Actions)
+}
+
+// The causes of all actions, without decoding the build again.
+func buildCauses(actions []generalObj) ([]Cause, error) {
+	var raw []map[string]interface{}
+	for _, a := range actions {
+		raw = append(raw, a.Causes...)
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var causes []Cause
+	if err := json.Unmarshal(data, &causes); err != nil {
+		return nil, err
+	}
+	return causes, nil
+}
+
+// The first upstream cause, or nil.
+func upstreamCause(causes []Cause) *Cause {
+	for i := range causes {
+		if causes[i].Kind() == CauseUpstream {
+			return &causes[i]
+		}
+	}
+	return nil
+}
+
+// CauseChain follows upstream causes from the build back to the build that
+// was not started by another one. The first link is the build itself, the
+// last one has the root causes, e.g. a user or an SCM change. Builds
+// started by several upstream builds are followed through the first one. If
+// an upstream build was deleted, its link has no causes and ends the chain.
+func (b *Build) CauseChain() ([]CauseLink, error) {
+	return b.CauseChainContext(context.Background())
+}
+
+func (b *Build) CauseChainContext(ctx context.Context) ([]CauseLink, error) {
+	causes, err := b.GetTypedCausesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chain := []CauseLink{{Job: FullNameFromPath(b.Base), Number: b.Raw.Number, Causes: causes}}
+	seen := map[string]bool{}
+	tree := Fields("number").Nested("actions", Fields().Nested("causes", TreeOf(Cause{})))
+	for len(chain) < maxCauseChain {
+		upstream := upstreamCause(chain[len(chain)-1].Causes)
+		if upstream == nil {
+			break
+		}
+		base := JobPath(upstream.UpstreamProject) + "/" + strconv.FormatInt(upstream.UpstreamBuild, 10)
+		if seen[base] {
+			break
+		}
+		seen[base] = true
+
+		link := CauseLink{Job: upstream.UpstreamProject, Number: upstream.UpstreamBuild}
+		build := &Build{Jenkins: b.Jenkins, Raw: new(BuildResponse), Depth: 1, Base: base}
+		_, err := build.PollContext(ctx, tree)
+		if errors.Is(err, ErrNotFound) {
+			chain = append(chain, link)
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if link.Causes, err = buildCauses(build.Raw.Actions); err != nil {
+			return nil, err
+		}
+		chain = append(chain, link)
+	}
+	return chain, nil
+}
@@ -0,0 +1,61 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCauseChain(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/deploy/3/api/json":
+			w.Write([]byte(`{"number":3,"actions":[{},{"causes":[
+				{"_class":"hudson.model.Cause$RemoteCause","addr":"10.0.0.1"},
+				{"_class":"org.jenkinsci.plugins.workflow.support.steps.build.BuildUpstreamCause","upstreamProject":"team/build","upstreamBuild":12,"upstreamUrl":"job/team/job/build/"}]}]}`))
+		case "/job/team/job/build/12/api/json":
+			assert.Contains(t, req.URL.Query().Get("tree"), "causes[_class,")
+			w.Write([]byte(`{"number":12,"actions":[{"causes":[{"_class":"hudson.model.Cause$UpstreamCause","upstreamProject":"team/lib","upstreamBuild":4}]}]}`))
+		case "/job/team/job/lib/4/api/json":
+			w.Write([]byte(`{"number":4,"actions":[{"causes":[{"_class":"hudson.model.Cause$UserIdCause","userId":"sam","userName":"Sam"}]}]}`))
+		case "/job/orphan/1/api/json":
+			w.Write([]byte(`{"number":1,"actions":[{"causes":[{"_class":"hudson.model.Cause$UpstreamCause","upstreamProject":"gone","upstreamBuild":5}]}]}`))
+		default:
+			http.NotFound(w, req)
+		}
+	})
+	defer done()
+	jenkins := &Jenkins{Requester: r}
+	build := &Build{Jenkins: jenkins, Raw: new(BuildResponse), Base: "/job/deploy/3"}
+
+	causes, err := build.GetTypedCauses()
+	assert.Nil(t, err)
+	assert.Equal(t, CauseRemote, causes[0].Kind())
+	assert.Equal(t, CauseUpstream, causes[1].Kind())
+	number, err := build.GetUpstreamBuildNumber()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), number)
+
+	chain, err := build.CauseChain()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(chain))
+	assert.Equal(t, "deploy", chain[0].Job)
+	assert.Equal(t, "team/build", chain[1].Job)
+	root := chain[2]
+	assert.Equal(t, "team/lib", root.Job)
+	assert.Equal(t, int64(4), root.Number)
+	assert.Equal(t, CauseUser, root.Causes[0].Kind())
+	assert.Equal(t, "sam", root.Causes[0].UserID)
+
+	// The upstream build was deleted.
+	build = &Build{Jenkins: jenkins, Raw: new(BuildResponse), Base: "/job/orphan/1"}
+	chain, err = build.CauseChain()
+	assert.Nil(t, err)
+	assert.Equal(t, []CauseLink{chain[0], {Job: "gone", Number: 5}}, chain)
+
+	build = &Build{Jenkins: jenkins, Raw: new(BuildResponse), Base: "/job/missing/1"}
+	_, err = build.CauseChain()
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,360 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of ValueChange.
+const (
+	CHANGE_ADDED   = "added"
+	CHANGE_REMOVED = "removed"
+	CHANGE_CHANGED = "changed"
+)
+
+// BuildDiff is what changed from build From to build To, see CompareBuilds.
+// It marshals to JSON, String renders it as text.
+type BuildDiff struct {
+	From   int64        `json:"from"`
+	To     int64        `json:"to"`
+	Result *ValueChange `json:"result,omitempty"`
+	// The name of the agent, or "built-in" for the controller.
+	BuiltOn    *ValueChange  `json:"builtOn,omitempty"`
+	Parameters []ValueChange `json:"parameters,omitempty"`
+	// Commits of the builds after From up to To, oldest first.
+	Commits []CommitInfo  `json:"commits,omitempty"`
+	EnvVars []ValueChange `json:"envVars,omitempty"`
+	// Full names of tests failing in To that did not fail in From, and the
+	// other way around.
+	NewFailures []string `json:"newFailures,omitempty"`
+	Fixed       []string `json:"fixed,omitempty"`
+	// Relative paths of the artifacts only one of the builds has.
+	ArtifactsAdded   []string `json:"artifactsAdded,omitempty"`
+	ArtifactsRemoved []string `json:"artifactsRemoved,omitempty"`
+	// Duration of To minus duration of From, in milliseconds.
+	DurationDelta int64 `json:"durationDelta"`
+}
+
+// ValueChange is a value that differs between two builds. Name is empty for
+// values without one, such as BuiltOn.
+type ValueChange struct {
+	Name   string `json:"name,omitempty"`
+	Change string `json:"change"` // CHANGE_ADDED, CHANGE_REMOVED or CHANGE_CHANGED
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+type CommitInfo struct {
+	ID      string `json:"id"`
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+// Environment variables that differ for every build.
+var perBuildEnvVars = []string{"BUILD_DISPLAY_NAME", "BUILD_ID", "BUILD_NUMBER", "BUILD_TAG", "BUILD_URL", "EXECUTOR_NUMBER"}
+
+// Only the case fields needed to compare test results.
+var compareTestsTree = Fields().Nested("suites", Fields().Nested("cases", Fields("className", "name", "status")))
+
+// CompareBuilds compares two polled builds, typically a broken build b and
+// the last good build a before it. The commits are gathered from the
+// changesets of all builds after a up to b, reading those in between from the
+// job's build list a page at a time.
+// Injected environment variables are only compared if the EnvInject plugin is
+// installed, and tests only if both builds have a test report.
+func CompareBuilds(a, b *Build) (*BuildDiff, error) {
+	return CompareBuildsContext(context.Background(), a, b)
+}
+
+func CompareBuildsContext(ctx context.Context, a, b *Build) (*BuildDiff, error) {
+	diff := &BuildDiff{
+		From:          a.Raw.Number,
+		To:            b.Raw.Number,
+		Result:        compareValue("", a.Raw.Result, b.Raw.Result),
+		BuiltOn:       compareValue("", builtOn(a), builtOn(b)),
+		Parameters:    compareMaps(parameterMap(a), parameterMap(b)),
+		DurationDelta: b.Raw.Duration - a.Raw.Duration,
+	}
+
+	commits, err := commitsSince(ctx, a, b)
+	if err != nil {
+		return nil, err
+	}
+	diff.Commits = commits
+
+	diff.ArtifactsAdded, diff.ArtifactsRemoved = compareSets(artifactPaths(a), artifactPaths(b))
+
+	envA, err := a.GetInjectedEnvVarsContext(ctx)
+	if err == nil {
+		var envB map[string]string
+		if envB, err = b.GetInjectedEnvVarsContext(ctx); err == nil {
+			for _, name := range perBuildEnvVars {
+				delete(envA, name)
+				delete(envB, name)
+			}
+			diff.EnvVars = compareMaps(envA, envB)
+		}
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	testsA, err := a.GetResultSetContext(ctx, compareTestsTree)
+	if err == nil {
+		var testsB *TestResult
+		if testsB, err = b.GetResultSetContext(ctx, compareTestsTree); err == nil {
+			diff.NewFailures, diff.Fixed = compareSets(failedTests(testsA), failedTests(testsB))
+		}
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// Builds read per request while looking for those between the compared ones.
+const compareBuildsPageSize = 100
+
+// Only the number and the changesets of the builds between the compared ones.
+var (
+	changeSetItemsTree = Fields().Nested("items", Fields("commitId", "id", "msg").Nested("author", Fields("fullName")))
+	changeSetTree      = Fields("number").Nested("changeSet", changeSetItemsTree).Nested("changeSets", changeSetItemsTree)
+)
+
+// The commits of the builds after a up to b, oldest first. If b is not a
+// later build of the same job, only its own commits.
+func commitsSince(ctx context.Context, a, b *Build) ([]CommitInfo, error) {
+	var commits []CommitInfo
+	seen := make(map[string]bool)
+	add := func(build *BuildResponse) {
+		for _, changeSet := range append([]BuildChangeSet{build.ChangeSet}, build.ChangeSets...) {
+			for _, item := range changeSet.Items {
+				if id := commitID(item.CommitId, item.ID); !seen[id] {
+					seen[id] = true
+					commits = append(commits, CommitInfo{ID: id, Author: item.Author.FullName, Message: item.Msg})
+				}
+			}
+		}
+	}
+	job := path.Dir(b.Base)
+	if path.Dir(a.Base) == job && b.Raw.Number-a.Raw.Number > 1 {
+		builds, err := buildsBetween(ctx, b.Jenkins, job, a.Raw.Number, b.Raw.Number)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(builds) - 1; i >= 0; i-- {
+			add(&builds[i])
+		}
+	}
+	add(b.Raw)
+	return commits, nil
+}
+
+// The changesets of the builds of job numbered after a and before b, newest
+// first. Deleted builds are not listed.
+func buildsBetween(ctx context.Context, jenkins *Jenkins, job string, a, b int64) ([]BuildResponse, error) {
+	var builds []BuildResponse
+	for from := 0; ; from += compareBuildsPageSize {
+		page := new(struct {
+			Builds []BuildResponse `json:"allBuilds"`
+		})
+		// Builds move down the list as new ones start, the pages must be
+		// read now.
+		tree := Fields().Nested("allBuilds", changeSetTree).Range(from, from+compareBuildsPageSize)
+		if _, err := jenkins.Requester.GetJSONContext(WithoutCache(ctx), job, page, map[string]string{"tree": tree.String()}); err != nil {
+			return nil, err
+		}
+		for _, build := range page.Builds {
+			if build.Number <= a {
+				return builds, nil
+			}
+			if build.Number < b {
+				builds = append(builds, build)
+			}
+		}
+		if len(page.Builds) < compareBuildsPageSize {
+			return builds, nil
+		}
+	}
+}
+
+func compareValue(name, from, to string) *ValueChange {
+	switch {
+	case from == to:
+		return nil
+	case from == "":
+		return &ValueChange{Name: name, Change: CHANGE_ADDED, To: to}
+	case to == "":
+		return &ValueChange{Name: name, Change: CHANGE_REMOVED, From: from}
+	}
+	return &ValueChange{Name: name, Change: CHANGE_CHANGED, From: from, To: to}
+}
+
+// The changed values of from and to, sorted by name.
+func compareMaps(from, to map[string]string) []ValueChange {
+	var changes []ValueChange
+	for name, value := range from {
+		other, ok := to[name]
+		if !ok {
+			changes = append(changes, ValueChange{Name: name, Change: CHANGE_REMOVED, From: value})
+		} else if other != value {
+			changes = append(changes, ValueChange{Name: name, Change: CHANGE_CHANGED, From: value, To: other})
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, ValueChange{Name: name, Change: CHANGE_ADDED, To: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// The sorted members of only to and of only from.
+func compareSets(from, to map[string]bool) (added, removed []string) {
+	for name := range to {
+		if !from[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range from {
+		if !to[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func builtOn(b *Build) string {
+	if b.Raw.BuiltOn == "" {
+		return "built-in"
+	}
+	return b.Raw.BuiltOn
+}
+
+func parameterMap(b *Build) map[string]string {
+	params := make(map[string]string)
+	for _, p := range b.GetParameters() {
+		params[p.Name] = p.Value
+	}
+	return params
+}
+
+func artifactPaths(b *Build) map[string]bool {
+	paths := make(map[string]bool)
+	for _, a := range b.Raw.Artifacts {
+		paths[a.RelativePath] = true
+	}
+	return paths
+}
+
+func failedTests(r *TestResult) map[string]bool {
+	failed := make(map[string]bool)
+	for _, c := range r.Failures() {
+		failed[c.GetFullName()] = true
+	}
+	return failed
+}
+
+func commitID(commitID, id string) string {
+	if commitID != "" {
+		return commitID
+	}
+	return id
+}
+
+// String renders the diff as text, one section per kind of change.
+func (d *BuildDiff) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "#%d -> #%d\n", d.From, d.To)
+	delta := time.Duration(d.DurationDelta) * time.Millisecond
+	if delta >= 0 {
+		fmt.Fprintf(&s, "Duration: +%s\n", delta)
+	} else {
+		fmt.Fprintf(&s, "Duration: %s\n", delta)
+	}
+	if d.Result != nil {
+		fmt.Fprintf(&s, "Result: %s\n", d.Result.String())
+	}
+	if d.BuiltOn != nil {
+		fmt.Fprintf(&s, "Built on: %s\n", d.BuiltOn.String())
+	}
+	writeChanges(&s, "Parameters", d.Parameters)
+	if len(d.Commits) > 0 {
+		s.WriteString("Commits:\n")
+		for _, c := range d.Commits {
+			fmt.Fprintf(&s, "  %s %s: %s\n", c.ID, c.Author, strings.TrimSpace(c.Message))
+		}
+	}
+	writeChanges(&s, "Environment", d.EnvVars)
+	writeList(&s, "New failures", "  ", d.NewFailures)
+	writeList(&s, "Fixed", "  ", d.Fixed)
+	if len(d.ArtifactsAdded) > 0 || len(d.ArtifactsRemoved) > 0 {
+		s.WriteString("Artifacts:\n")
+		writeList(&s, "", "  + ", d.ArtifactsAdded)
+		writeList(&s, "", "  - ", d.ArtifactsRemoved)
+	}
+	return s.String()
+}
+
+// String renders the change as "from -> to", or the value added or removed
+// with a + or - in front. Named changes get a ~ in front as well.
+func (c *ValueChange) String() string {
+	prefix := ""
+	if c.Name != "" {
+		prefix = c.Name + ": "
+	}
+	switch c.Change {
+	case CHANGE_ADDED:
+		return "+ " + prefix + c.To
+	case CHANGE_REMOVED:
+		return "- " + prefix + c.From
+	}
+	if c.Name != "" {
+		prefix = "~ " + prefix
+	}
+	return prefix + c.From + " -> " + c.To
+}
+
+func writeChanges(s *strings.Builder, title string, changes []ValueChange) {
+	if len(changes) == 0 {
+		return
+	}
+	s.WriteString(title + ":\n")
+	for i := range changes {
+		s.WriteString("  " + changes[i].String() + "\n")
+	}
+}
+
+func writeList(s *strings.Builder, title string, prefix string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	if title != "" {
+		s.WriteString(title + ":\n")
+	}
+	for _, item := range items {
+		s.WriteString(prefix + item + "\n")
+	}
+}
@@ -0,0 +1,90 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareBuilds(t *testing.T) {
+	var trees []string
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/a/1/injectedEnvVars/api/json":
+			w.Write([]byte(`{"envMap":{"BUILD_NUMBER":"1","JAVA_HOME":"/opt/jdk11","OLD":"x"}}`))
+		case "/job/a/2/injectedEnvVars/api/json":
+			w.Write([]byte(`{"envMap":{"BUILD_NUMBER":"2","JAVA_HOME":"/opt/jdk17"}}`))
+		case "/job/a/1/testReport/api/json":
+			w.Write([]byte(`{"suites":[{"cases":[{"className":"a.B","name":"one","status":"FAILED"},{"className":"a.B","name":"two","status":"PASSED"}]}]}`))
+		case "/job/a/2/testReport/api/json":
+			w.Write([]byte(`{"suites":[{"cases":[{"className":"a.B","name":"one","status":"FIXED"},{"className":"a.B","name":"two","status":"REGRESSION"}]}]}`))
+		case "/job/c/api/json":
+			trees = append(trees, req.URL.Query().Get("tree"))
+			// Build 2 was deleted, 3 is a Pipeline build with a changeset
+			// per repository.
+			w.Write([]byte(`{"allBuilds":[{"number":5},{"number":4},
+				{"number":3,"changeSets":[{"items":[{"commitId":"ccc","msg":"Add cache"}]},{"items":[{"commitId":"ddd","msg":"Update docs"}]}]},
+				{"number":1,"changeSet":{"items":[{"commitId":"aaa","msg":"old"}]}}]}`))
+		default:
+			http.NotFound(w, req)
+		}
+	})
+	defer done()
+	jenkins := &Jenkins{Requester: r}
+	a := &Build{Jenkins: jenkins, Raw: new(BuildResponse), Base: "/job/a/1"}
+	b := &Build{Jenkins: jenkins, Raw: new(BuildResponse), Base: "/job/a/2"}
+	assert.Nil(t, json.Unmarshal([]byte(`{"number":1,"result":"SUCCESS","duration":60000,
+		"actions":[{"parameters":[{"name":"VERSION","value":"1.0"},{"name":"DEBUG","value":false}]}],
+		"changeSet":{"items":[{"commitId":"aaa","msg":"old"}]},
+		"artifacts":[{"relativePath":"app.jar"},{"relativePath":"old.txt"}]}`), a.Raw))
+	assert.Nil(t, json.Unmarshal([]byte(`{"number":2,"result":"FAILURE","builtOn":"agent-2","duration":45000,
+		"actions":[{"parameters":[{"name":"VERSION","value":"1.1"},{"name":"DEBUG","value":false}]}],
+		"changeSet":{"items":[{"commitId":"bbb","msg":"Bump JDK\n","author":{"fullName":"Sam"}}]},
+		"artifacts":[{"relativePath":"app.jar"},{"relativePath":"new.txt"}]}`), b.Raw))
+
+	diff, err := CompareBuilds(a, b)
+	assert.Nil(t, err)
+	assert.Equal(t, &ValueChange{Change: CHANGE_CHANGED, From: "built-in", To: "agent-2"}, diff.BuiltOn)
+	assert.Equal(t, []ValueChange{{Name: "VERSION", Change: CHANGE_CHANGED, From: "1.0", To: "1.1"}}, diff.Parameters)
+	assert.Equal(t, []string{"a.B.two"}, diff.NewFailures)
+	assert.Equal(t, `#1 -> #2
+Duration: -15s
+Result: SUCCESS -> FAILURE
+Built on: built-in -> agent-2
+Parameters:
+  ~ VERSION: 1.0 -> 1.1
+Commits:
+  bbb Sam: Bump JDK
+Environment:
+  ~ JAVA_HOME: /opt/jdk11 -> /opt/jdk17
+  - OLD: x
+New failures:
+  a.B.two
+Fixed:
+  a.B.one
+Artifacts:
+  + new.txt
+  - old.txt
+`, diff.String())
+
+	data, err := json.Marshal(diff)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"newFailures":["a.B.two"],"fixed":["a.B.one"],"artifactsAdded":["new.txt"],"artifactsRemoved":["old.txt"],"durationDelta":-15000}`)
+
+	// Without EnvInject and test reports.
+	a.Base, b.Base = "/job/b/1", "/job/b/2"
+	diff, err = CompareBuilds(a, b)
+	assert.Nil(t, err)
+	assert.Nil(t, diff.EnvVars)
+	assert.Nil(t, diff.NewFailures)
+
+	// Commits of the builds in between are included, deleted builds skipped.
+	a.Base, b.Base = "/job/c/1", "/job/c/4"
+	b.Raw.Number = 4
+	diff, err = CompareBuilds(a, b)
+	assert.Nil(t, err)
+	assert.Equal(t, []CommitInfo{{ID: "ccc", Message: "Add cache"}, {ID: "ddd", Message: "Update docs"}, {ID: "bbb", Author: "Sam", Message: "Bump JDK\n"}}, diff.Commits)
+	assert.Equal(t, []string{"allBuilds[number,changeSet[items[commitId,id,msg,author[fullName]]],changeSets[items[commitId,id,msg,author[fullName]]]]{0,100}"}, trees)
+}
@@ -0,0 +1,108 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+)
+
+const defaultConsolePollInterval = time.Second
+
+// ConsoleLog reads the console log of a build as it is written, using the
+// progressiveText endpoint, or progressiveHtml for the annotated HTML the
+// Jenkins UI shows. Offset and Annotator are all the state there is, save
+// them to continue reading later from where you left off:
+//
+//	log := &gojenkins.ConsoleLog{Build: build, Offset: saved}
+//	err := log.Stream(ctx, os.Stdout)
+//	saved = log.Offset
+type ConsoleLog struct {
+	Build *Build
+	// Byte offset of the next chunk to read.
+	Offset int64
+	// Read progressiveHtml instead of progressiveText.
+	HTML bool
+	// State of the console annotators, sent back with the next HTML request.
+	Annotator string
+	// Time to wait for new output while the build is running.
+	PollInterval time.Duration
+}
+
+// StreamConsole copies the console log to w as it is written, until the build
+// has finished or ctx is done.
+func (b *Build) StreamConsole(ctx context.Context, w io.Writer) error {
+	return (&ConsoleLog{Build: b}).Stream(ctx, w)
+}
+
+// Next copies the output written since Offset to w, and advances Offset. more
+// is true if the build is still running and there may be more output.
+func (c *ConsoleLog) Next(w io.Writer) (more bool, err error) {
+	return c.NextContext(context.Background(), w)
+}
+
+func (c *ConsoleLog) NextContext(ctx context.Context, w io.Writer) (more bool, err error) {
+	endpoint := c.Build.Base + "/logText/progressiveText"
+	if c.HTML {
+		endpoint = c.Build.Base + "/logText/progressiveHtml"
+	}
+	ar := NewAPIRequest("GET", endpoint, nil)
+	ar.Suffix = ""
+	if c.HTML && c.Annotator != "" {
+		ar.SetHeader("X-ConsoleAnnotator", c.Annotator)
+	}
+	var body io.ReadCloser
+	response, err := c.Build.Jenkins.Requester.DoContext(ctx, ar, &body, map[string]string{"start": strconv.FormatInt(c.Offset, 10)})
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+	if _, err := io.Copy(w, body); err != nil {
+		return false, err
+	}
+
+	if size, err := strconv.ParseInt(response.Header.Get("X-Text-Size"), 10, 64); err == nil {
+		c.Offset = size
+	}
+	if annotator := response.Header.Get("X-ConsoleAnnotator"); annotator != "" {
+		c.Annotator = annotator
+	}
+	return response.Header.Get("X-More-Data") == "true", nil
+}
+
+// Stream copies the console log to w from Offset on, until the build has
+// finished or ctx is done. Offset is kept up to date, so the log can be
+// streamed on from there after an error.
+func (c *ConsoleLog) Stream(ctx context.Context, w io.Writer) error {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = defaultConsolePollInterval
+	}
+	for {
+		offset := c.Offset
+		more, err := c.NextContext(ctx, w)
+		if err != nil || !more {
+			return err
+		}
+		// Fetch right away while there is output, wait for more otherwise.
+		if c.Offset == offset {
+			if err := sleepContext(ctx, interval); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package gojenkins
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamConsole(t *testing.T) {
+	log := "Started\nBuilding\nFinished: SUCCESS\n"
+	chunks := []int{8, 8, 17, len(log)}
+	var starts []string
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		start, _ := strconv.Atoi(req.URL.Query().Get("start"))
+		starts = append(starts, req.URL.Query().Get("start"))
+		if strings.Contains(req.URL.Path, "progressiveHtml") {
+			assert.Equal(t, "", req.Header.Get("X-ConsoleAnnotator"))
+			w.Header().Set("X-ConsoleAnnotator", "state")
+		}
+		end := chunks[0]
+		if len(chunks) > 1 {
+			chunks = chunks[1:]
+			w.Header().Set("X-More-Data", "true")
+		}
+		w.Header().Set("X-Text-Size", strconv.Itoa(end))
+		w.Write([]byte(log[start:end]))
+	})
+	defer done()
+	build := &Build{Jenkins: &Jenkins{Requester: r}, Raw: new(BuildResponse), Base: "/job/a/7"}
+
+	var out bytes.Buffer
+	console := &ConsoleLog{Build: build, PollInterval: time.Millisecond}
+	assert.Nil(t, console.Stream(context.Background(), &out))
+	assert.Equal(t, log, out.String())
+	assert.Equal(t, []string{"0", "8", "8", "17"}, starts)
+	assert.Equal(t, int64(len(log)), console.Offset)
+
+	// Resume from a saved offset.
+	out.Reset()
+	console = &ConsoleLog{Build: build, Offset: 17, HTML: true}
+	more, err := console.Next(&out)
+	assert.Nil(t, err)
+	assert.False(t, more)
+	assert.Equal(t, "Finished: SUCCESS\n", out.String())
+	assert.Equal(t, "state", console.Annotator)
+}
@@ -12,4 +12,8 @@ const (
 	RESULT_STATUS_FAILED  = "FAILED"
 	RESULT_STATUS_SKIPPED = "SKIPPED"
 	STR_RE_SPLIT_VIEW     = "(.*)/view/([^/]*)/?"
+
+	PIPELINE_STATUS_IN_PROGRESS  = "IN_PROGRESS"
+	PIPELINE_STATUS_PAUSED       = "PAUSED_PENDING_INPUT"
+	PIPELINE_STATUS_NOT_EXECUTED = "NOT_EXECUTED"
 )
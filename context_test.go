@@ -0,0 +1,36 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCancel(t *testing.T) {
+	started := make(chan struct{}, 2)
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-req.Context().Done()
+	})
+	defer done()
+	jenkins := &Jenkins{Requester: r}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := jenkins.GetJobContext(ctx, "app")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err = jenkins.BuildJobContext(ctx, "app", nil)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
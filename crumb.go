@@ -0,0 +1,136 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CSRF crumb issued by Jenkins. Since Jenkins 2.176 crumbs are only valid for
+// the web session they were issued in, so the session cookie is kept along.
+type crumb struct {
+	RequestField string `json:"crumbRequestField"`
+	Value        string `json:"crumb"`
+	session      string
+}
+
+func (r *Requester) SetCrumb(ar *APIRequest) error {
+	return r.SetCrumbContext(context.Background(), ar)
+}
+
+// SetCrumbContext adds the CSRF crumb header to ar. The crumb is fetched once
+// and reused for as long as the session in the client's cookie jar lasts.
+func (r *Requester) SetCrumbContext(ctx context.Context, ar *APIRequest) error {
+	c, err := r.getCrumb(ctx)
+	if err != nil {
+		return err
+	}
+	if c.RequestField != "" {
+		ar.SetHeader(c.RequestField, c.Value)
+	}
+	return nil
+}
+
+// InvalidateCrumb drops the cached crumb, the next POST fetches a new one.
+// This happens automatically when Jenkins rejects a crumb.
+func (r *Requester) InvalidateCrumb() {
+	r.crumbMu.Lock()
+	defer r.crumbMu.Unlock()
+	r.crumb = nil
+}
+
+// A crumb fetch in progress, shared by the requests waiting for it.
+type crumbFetch struct {
+	done  chan struct{}
+	crumb *crumb
+	err   error
+}
+
+// Return the cached crumb, or fetch a new one. The lock is not held while
+// fetching, so the fetch cannot block InvalidateCrumb, and concurrent
+// callers wait for the same fetch rather than each starting a session.
+func (r *Requester) getCrumb(ctx context.Context) (*crumb, error) {
+	for {
+		r.crumbMu.Lock()
+		if r.crumb != nil && r.crumb.session == r.session() {
+			c := r.crumb
+			r.crumbMu.Unlock()
+			return c, nil
+		}
+		fetch := r.crumbFetch
+		if fetch == nil {
+			fetch = &crumbFetch{done: make(chan struct{})}
+			r.crumbFetch = fetch
+			r.crumbMu.Unlock()
+
+			fetch.crumb, fetch.err = r.fetchCrumb(ctx)
+			r.crumbMu.Lock()
+			if fetch.err == nil {
+				r.crumb = fetch.crumb
+			}
+			r.crumbFetch = nil
+			r.crumbMu.Unlock()
+			close(fetch.done)
+			return fetch.crumb, fetch.err
+		}
+		r.crumbMu.Unlock()
+
+		select {
+		case <-fetch.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// The context of the caller that fetched may have ended, try again
+		// with ours.
+		if fetch.err != nil && ctx.Err() == nil && (errors.Is(fetch.err, context.Canceled) || errors.Is(fetch.err, context.DeadlineExceeded)) {
+			continue
+		}
+		return fetch.crumb, fetch.err
+	}
+}
+
+func (r *Requester) fetchCrumb(ctx context.Context) (*crumb, error) {
+	c := new(crumb)
+	_, err := r.GetJSONContext(ctx, "/crumbIssuer", c, nil)
+	// No crumb issuer means CSRF protection is disabled, remember that as well.
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("jenkins: could not fetch crumb: %w", err)
+	}
+	// Fetching the crumb may have started a new session.
+	c.session = r.session()
+	return c, nil
+}
+
+// Identify the current web session by the session cookies in the client's jar.
+func (r *Requester) session() string {
+	if r.Client == nil || r.Client.Jar == nil {
+		return ""
+	}
+	u, err := url.Parse(r.Base)
+	if err != nil {
+		return ""
+	}
+	var ids []string
+	for _, cookie := range r.Client.Jar.Cookies(u) {
+		if strings.HasPrefix(cookie.Name, "JSESSIONID") {
+			ids = append(ids, cookie.Name+"="+cookie.Value)
+		}
+	}
+	return strings.Join(ids, ";")
+}
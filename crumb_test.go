@@ -0,0 +1,108 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrumbCache(t *testing.T) {
+	crumbs := 0
+	restarted := false
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/crumbIssuer/api/json":
+			crumbs++
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID.abc", Value: strconv.Itoa(crumbs), Path: "/"})
+			w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"` + strconv.Itoa(crumbs) + `"}`))
+		case "/job/a/build":
+			session, _ := req.Cookie("JSESSIONID.abc")
+			if restarted || session == nil || session.Value != req.Header.Get("Jenkins-Crumb") {
+				restarted = false
+				http.Error(w, "No valid crumb was included in the request", http.StatusForbidden)
+			}
+		}
+	})
+	defer done()
+	r.Client.Jar, _ = cookiejar.New(nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := r.Post("/job/a/build", nil, nil, nil)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 1, crumbs)
+
+	// A new session needs a new crumb.
+	u, _ := url.Parse(r.Base)
+	r.Client.Jar.SetCookies(u, []*http.Cookie{{Name: "JSESSIONID.abc", Value: "other", Path: "/"}})
+	_, err := r.Post("/job/a/build", nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, crumbs)
+
+	// A rejected crumb is dropped and fetched again by the next request.
+	restarted = true
+	_, err = r.Post("/job/a/build", nil, nil, nil)
+	assert.True(t, errors.Is(err, ErrCrumbInvalid))
+	_, err = r.Post("/job/a/build", nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, crumbs)
+}
+
+func TestCrumbIssuerRejected(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "No valid crumb was included in the request", http.StatusForbidden)
+	})
+	defer done()
+	r.Retry = &RetryPolicy{MaxAttempts: 3}
+
+	result := make(chan error)
+	go func() {
+		_, err := r.Post("/job/a/build", nil, nil, nil)
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		assert.True(t, errors.Is(err, ErrCrumbInvalid))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Post did not return")
+	}
+}
+
+func TestCrumbConcurrentFetch(t *testing.T) {
+	var mu sync.Mutex
+	crumbs := 0
+	release := make(chan struct{})
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/crumbIssuer/api/json" {
+			mu.Lock()
+			crumbs++
+			mu.Unlock()
+			<-release
+			w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"c"}`))
+		}
+	})
+	defer done()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.Post("/job/a/build", nil, nil, nil)
+			assert.Nil(t, err)
+		}()
+	}
+	// A slow crumb fetch does not block invalidating the crumb.
+	time.Sleep(20 * time.Millisecond)
+	r.InvalidateCrumb()
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, crumbs)
+}
@@ -0,0 +1,247 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"strconv"
+)
+
+const (
+	defaultDownstreamWindow = 100
+	// Builds fetched per request while scanning a downstream job.
+	downstreamPageSize = 25
+)
+
+// DownstreamOptions bounds the search for downstream builds. The zero value
+// looks at the last 100 builds of each downstream job.
+type DownstreamOptions struct {
+	// Look at no more than this many of the newest builds of each
+	// downstream job.
+	Window int
+	// Levels of downstream builds GetDownstreamGraph follows, 0 means all.
+	MaxDepth int
+}
+
+// BuildNode is a build in the graph returned by GetDownstreamGraph.
+type BuildNode struct {
+	Build      *Build       `json:"-"`
+	Job        string       `json:"job"` // full name
+	Number     int64        `json:"number"`
+	Result     string       `json:"result"`
+	URL        string       `json:"url"`
+	Downstream []*BuildNode `json:"downstream,omitempty"`
+}
+
+// Only the fields needed to find the builds an upstream build started.
+var downstreamTree = Fields("number", "timestamp").Nested("actions", Fields().Nested("causes", Fields("_class", "upstreamProject", "upstreamBuild")))
+
+// FindDownstreamBuilds returns the builds this build started. Builds started
+// by the build step of a Pipeline are read from the build itself, those still
+// in the queue are left out. For the downstream projects of the job, their
+// builds are scanned newest first, a page at a time and only the fields
+// needed, until a build older than this one or the end of opts.Window. The
+// JSON API cannot filter on values, the upstream causes of the scanned builds
+// are matched here. opts may be nil.
+func (b *Build) FindDownstreamBuilds(opts *DownstreamOptions) ([]*Build, error) {
+	return b.FindDownstreamBuildsContext(context.Background(), opts)
+}
+
+func (b *Build) FindDownstreamBuildsContext(ctx context.Context, opts *DownstreamOptions) ([]*Build, error) {
+	r := newDownstreamResolver(b.Jenkins, opts)
+	return r.downstream(ctx, b)
+}
+
+// GetDownstreamGraph returns the build and, recursively, the builds started
+// by it, e.g. all builds of a pipeline of jobs. Builds reached on several
+// paths appear once, with the same node.
+func (b *Build) GetDownstreamGraph(opts *DownstreamOptions) (*BuildNode, error) {
+	return b.GetDownstreamGraphContext(context.Background(), opts)
+}
+
+func (b *Build) GetDownstreamGraphContext(ctx context.Context, opts *DownstreamOptions) (*BuildNode, error) {
+	r := newDownstreamResolver(b.Jenkins, opts)
+	if b.Raw.Number == 0 {
+		if _, err := b.PollContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return r.graph(ctx, b, 0, map[string]*BuildNode{})
+}
+
+type downstreamResolver struct {
+	jenkins *Jenkins
+	opts    *DownstreamOptions
+	// Jobs fetched so far, by full name.
+	jobs map[string]*Job
+}
+
+func newDownstreamResolver(j *Jenkins, opts *DownstreamOptions) *downstreamResolver {
+	if opts == nil {
+		opts = &DownstreamOptions{}
+	}
+	return &downstreamResolver{jenkins: j, opts: opts, jobs: map[string]*Job{}}
+}
+
+func (r *downstreamResolver) getJob(ctx context.Context, fullName string) (*Job, error) {
+	if job, ok := r.jobs[fullName]; ok {
+		return job, nil
+	}
+	job, err := r.jenkins.GetJobContext(ctx, fullName)
+	if err != nil {
+		return nil, err
+	}
+	r.jobs[fullName] = job
+	return job, nil
+}
+
+func (r *downstreamResolver) graph(ctx context.Context, b *Build, depth int, seen map[string]*BuildNode) (*BuildNode, error) {
+	node := &BuildNode{Build: b, Job: FullNameFromPath(b.Base), Number: b.Raw.Number, Result: b.Raw.Result, URL: b.Raw.URL}
+	seen[b.Base] = node
+	if r.opts.MaxDepth > 0 && depth >= r.opts.MaxDepth {
+		return node, nil
+	}
+	builds, err := r.downstream(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	for _, build := range builds {
+		child, ok := seen[build.Base]
+		if !ok {
+			if child, err = r.graph(ctx, build, depth+1, seen); err != nil {
+				return nil, err
+			}
+		}
+		node.Downstream = append(node.Downstream, child)
+	}
+	return node, nil
+}
+
+func (r *downstreamResolver) downstream(ctx context.Context, b *Build) ([]*Build, error) {
+	if b.Raw.Number == 0 {
+		if _, err := b.PollContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	fullName := FullNameFromPath(b.Base)
+	found := map[string]bool{}
+	var result []*Build
+	add := func(jobName string, number int64) error {
+		key := jobName + "#" + strconv.FormatInt(number, 10)
+		if found[key] {
+			return nil
+		}
+		found[key] = true
+		job, err := r.getJob(ctx, jobName)
+		if err != nil {
+			return err
+		}
+		build, err := job.GetBuildContext(ctx, number)
+		if err != nil {
+			return err
+		}
+		result = append(result, build)
+		return nil
+	}
+
+	for _, a := range b.Raw.Actions {
+		for _, d := range a.DownstreamBuilds {
+			// Still in the queue, there is no build yet.
+			if d.BuildNumber == 0 {
+				continue
+			}
+			if err := add(d.JobFullName, d.BuildNumber); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	job := b.Job
+	if job == nil || job.Raw.Name == "" {
+		var err error
+		if job, err = r.getJob(ctx, fullName); err != nil {
+			return nil, err
+		}
+	}
+	for _, project := range job.GetDownstreamJobsMetadata() {
+		name := project.GetFullName()
+		numbers, err := r.scan(ctx, name, fullName, b.Raw.Number, b.Raw.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		for _, number := range numbers {
+			if err := add(name, number); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// The numbers of the builds of job started by build number of upstream,
+// looking at builds newer than timestamp only. tree= narrows the fields sent,
+// the causes are compared here.
+func (r *downstreamResolver) scan(ctx context.Context, job string, upstream string, number int64, timestamp int64) ([]int64, error) {
+	window := r.opts.Window
+	if window <= 0 {
+		window = defaultDownstreamWindow
+	}
+	var numbers []int64
+	for from := 0; from < window; from += downstreamPageSize {
+		to := from + downstreamPageSize
+		if to > window {
+			to = window
+		}
+		page := new(struct {
+			Builds []struct {
+				Number    int64           `json:"number"`
+				Timestamp int64           `json:"timestamp"`
+				Actions   []generalAction `json:"actions"`
+			} `json:"allBuilds"`
+		})
+		// Builds started since the last scan must not be missed.
+		tree := Fields().Nested("allBuilds", downstreamTree).Range(from, to)
+		if _, err := r.jenkins.Requester.GetJSONContext(WithoutCache(ctx), JobPath(job), page, map[string]string{"tree": tree.String()}); err != nil {
+			return nil, err
+		}
+		for _, build := range page.Builds {
+			// Builds are listed newest first, older ones cannot have been
+			// started by the upstream build.
+			if build.Timestamp < timestamp {
+				return numbers, nil
+			}
+			if startedBy(build.Actions, upstream, number) {
+				numbers = append(numbers, build.Number)
+			}
+		}
+		if len(page.Builds) < to-from {
+			break
+		}
+	}
+	return numbers, nil
+}
+
+func startedBy(actions []generalAction, job string, number int64) bool {
+	for _, a := range actions {
+		for _, cause := range a.Causes {
+			project, _ := cause["upstreamProject"].(string)
+			build, _ := cause["upstreamBuild"].(float64)
+			if project == job && int64(build) == number {
+				return true
+			}
+		}
+	}
+	return false
+}
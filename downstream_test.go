@@ -0,0 +1,64 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownstreamGraph(t *testing.T) {
+	var server *httptest.Server
+	var trees []string
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/up/api/json":
+			w.Write([]byte(`{"name":"up","downstreamProjects":[{"name":"down","url":"` + server.URL + `/job/down/"}]}`))
+		case "/job/up/5/api/json":
+			w.Write([]byte(`{"number":5,"timestamp":1000,"result":"SUCCESS","actions":[{"_class":"org.jenkinsci.plugins.workflow.support.steps.build.DownstreamBuildAction",
+				"downstreamBuilds":[{"jobFullName":"team/deploy","buildNumber":2},{"jobFullName":"team/notify","buildNumber":null}]}]}`))
+		case "/job/down/api/json":
+			if tree := req.URL.Query().Get("tree"); tree != "" {
+				trees = append(trees, tree)
+				cause := func(n int) string {
+					return `"actions":[{"causes":[{"upstreamProject":"up","upstreamBuild":` + strconv.Itoa(n) + `}]}]`
+				}
+				w.Write([]byte(`{"allBuilds":[{"number":12,"timestamp":3000,` + cause(6) + `},{"number":11,"timestamp":2000,` + cause(5) + `},
+					{"number":10,"timestamp":1500,` + cause(5) + `},{"number":9,"timestamp":900,` + cause(5) + `}]}`))
+				return
+			}
+			w.Write([]byte(`{"name":"down"}`))
+		case "/job/down/11/api/json", "/job/down/10/api/json":
+			w.Write([]byte(`{"number":` + strings.Split(req.URL.Path, "/")[3] + `,"result":"FAILURE"}`))
+		case "/job/team/job/deploy/api/json":
+			w.Write([]byte(`{"name":"deploy"}`))
+		case "/job/team/job/deploy/2/api/json":
+			w.Write([]byte(`{"number":2,"timestamp":1200}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+	jenkins := &Jenkins{Server: server.URL, Requester: &Requester{Base: server.URL, Client: server.Client()}}
+	build := &Build{Jenkins: jenkins, Raw: new(BuildResponse), Base: "/job/up/5"}
+
+	graph, err := build.GetDownstreamGraph(&DownstreamOptions{Window: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, "up", graph.Job)
+	var names []string
+	for _, node := range graph.Downstream {
+		names = append(names, node.Job+"#"+strconv.FormatInt(node.Number, 10))
+	}
+	// team/notify has not left the queue yet.
+	assert.Equal(t, []string{"team/deploy#2", "down#11", "down#10"}, names)
+	assert.Equal(t, "FAILURE", graph.Downstream[1].Result)
+	// Build 9 is older than the upstream build, so it was not a match.
+	assert.Equal(t, []string{"allBuilds[number,timestamp,actions[causes[_class,upstreamProject,upstreamBuild]]]{0,4}"}, trees)
+
+	builds, err := build.GetDownstreamBuilds()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(builds))
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by APIError. Use errors.Is to check for them:
+//
+//	if errors.Is(err, gojenkins.ErrNotFound) { ... }
+var (
+	ErrNotFound     = errors.New("jenkins: not found")
+	ErrUnauthorized = errors.New("jenkins: unauthorized")
+	ErrForbidden    = errors.New("jenkins: forbidden")
+	ErrCrumbInvalid = errors.New("jenkins: no valid crumb")
+	ErrServerError  = errors.New("jenkins: server error")
+)
+
+// Maximum number of body bytes kept in APIError.Body.
+const maxErrorBodyExcerpt = 1024
+
+// APIError is returned when Jenkins answers with an error status code or sets
+// the X-Error header. Use errors.As to get at the details.
+type APIError struct {
+	StatusCode   int
+	Method       string
+	URL          string
+	JenkinsError string // Value of the X-Error header, if any.
+	Body         string // First bytes of the response body.
+}
+
+func (e *APIError) Error() string {
+	msg := e.JenkinsError
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("jenkins: %s %s: %d %s", e.Method, e.URL, e.StatusCode, msg)
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+// A 403 caused by a rejected crumb matches ErrCrumbInvalid, not ErrForbidden.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden && !e.crumbRejected()
+	case ErrCrumbInvalid:
+		return e.StatusCode == http.StatusForbidden && e.crumbRejected()
+	case ErrServerError:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+func (e *APIError) crumbRejected() bool {
+	return strings.Contains(e.JenkinsError, "No valid crumb") || strings.Contains(e.Body, "No valid crumb")
+}
+
+// Build an APIError from a response. The body is read up to
+// maxErrorBodyExcerpt bytes, if it has not been consumed yet.
+func newAPIError(response *http.Response) *APIError {
+	e := &APIError{
+		StatusCode:   response.StatusCode,
+		JenkinsError: response.Header.Get("X-Error"),
+	}
+	if response.Request != nil {
+		e.Method = response.Request.Method
+		e.URL = response.Request.URL.String()
+	}
+	if response.Body != nil {
+		excerpt, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBodyExcerpt))
+		e.Body = string(excerpt)
+	}
+	// Jetty puts the reason into the status line, e.g. "403 No valid crumb was included in the request".
+	if e.JenkinsError == "" && strings.Contains(response.Status, "No valid crumb") {
+		e.JenkinsError = strings.TrimSpace(strings.TrimPrefix(response.Status, fmt.Sprint(response.StatusCode)))
+	}
+	return e
+}
+
+// Build an APIError for a request whose status code was not an error but
+// still not what the caller expected.
+func (r *Requester) statusError(method string, endpoint string, status int) *APIError {
+	return &APIError{StatusCode: status, Method: method, URL: r.Base + endpoint}
+}
@@ -15,6 +15,7 @@
 package gojenkins
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -46,7 +47,11 @@ type fingerPrintResponse struct {
 }
 
 func (f Fingerprint) Valid() (bool, error) {
-	status, err := f.Poll()
+	return f.ValidContext(context.Background())
+}
+
+func (f Fingerprint) ValidContext(ctx context.Context) (bool, error) {
+	status, err := f.PollContext(ctx)
 
 	if err != nil {
 		return false, err
@@ -59,7 +64,11 @@ func (f Fingerprint) Valid() (bool, error) {
 }
 
 func (f Fingerprint) ValidateForBuild(filename string, build *Build) (bool, error) {
-	valid, err := f.Valid()
+	return f.ValidateForBuildContext(context.Background(), filename, build)
+}
+
+func (f Fingerprint) ValidateForBuildContext(ctx context.Context, filename string, build *Build) (bool, error) {
+	valid, err := f.ValidContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -71,7 +80,7 @@ func (f Fingerprint) ValidateForBuild(filename string, build *Build) (bool, erro
 	if f.Raw.FileName != filename {
 		return false, errors.New("Filename does not Match")
 	}
-	if build != nil && f.Raw.Original.Name == build.Job.GetName() &&
+	if build != nil && f.Raw.Original.Name == build.Job.GetFullName() &&
 		f.Raw.Original.Number == build.GetBuildNumber() {
 		return true, nil
 	}
@@ -79,15 +88,23 @@ func (f Fingerprint) ValidateForBuild(filename string, build *Build) (bool, erro
 }
 
 func (f Fingerprint) GetInfo() (*fingerPrintResponse, error) {
-	_, err := f.Poll()
+	return f.GetInfoContext(context.Background())
+}
+
+func (f Fingerprint) GetInfoContext(ctx context.Context) (*fingerPrintResponse, error) {
+	_, err := f.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return f.Raw, nil
 }
 
-func (f Fingerprint) Poll() (int, error) {
-	response, err := f.Jenkins.Requester.GetJSON(f.Base+f.Id, f.Raw, nil)
+func (f Fingerprint) Poll(options ...interface{}) (int, error) {
+	return f.PollContext(context.Background(), options...)
+}
+
+func (f Fingerprint) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := f.Jenkins.Requester.GetJSONContext(ctx, f.Base+f.Id, f.Raw, pollQuery(options))
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,91 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Jobs inside folders are addressed by their full name, the names of the
+// enclosing folders and the job joined by slashes, e.g. "team/service/deploy".
+// This is the fullName Jenkins reports, and what every method taking a job
+// name accepts.
+
+// JobPath returns the endpoint of a job, e.g. "/job/team/job/service/job/deploy"
+// for "team/service/deploy". Each name is escaped on its own, so names with
+// spaces or the %2F of multibranch branch jobs keep working.
+func JobPath(fullName string) string {
+	var b strings.Builder
+	for _, name := range strings.Split(strings.Trim(fullName, "/"), "/") {
+		b.WriteString("/job/")
+		b.WriteString(url.PathEscape(name))
+	}
+	return b.String()
+}
+
+// FullNameFromPath returns the full name of the job an URL or endpoint points
+// to, e.g. "team/service/deploy" for
+// "https://jenkins.example.com/job/team/job/service/job/deploy/42/".
+// Anything before the first /job/ and after the last job name is ignored.
+func FullNameFromPath(u string) string {
+	if parsed, err := url.Parse(u); err == nil {
+		u = parsed.EscapedPath()
+	}
+	segments := strings.Split(u, "/")
+	var names []string
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] != "job" {
+			if len(names) > 0 {
+				break
+			}
+			continue
+		}
+		name, err := url.PathUnescape(segments[i+1])
+		if err != nil {
+			name = segments[i+1]
+		}
+		names = append(names, name)
+		i++
+	}
+	return strings.Join(names, "/")
+}
+
+// GetFullName returns the full name of the job, e.g. "team/service/deploy".
+func (ij InnerJob) GetFullName() string {
+	return FullNameFromPath(ij.Url)
+}
+
+// GetFullName returns the full name of the job, e.g. "team/service/deploy".
+func (j *Job) GetFullName() string {
+	if j.Raw != nil && j.Raw.FullName != "" {
+		return j.Raw.FullName
+	}
+	return FullNameFromPath(j.Base)
+}
+
+// The endpoint of an absolute URL pointing into this Jenkins, i.e. its path
+// without the path of j.Server. Other values are returned as they are.
+func (j *Jenkins) endpoint(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || !parsed.IsAbs() {
+		return u
+	}
+	path := parsed.EscapedPath()
+	if server, err := url.Parse(j.Server); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(server.EscapedPath(), "/"))
+	}
+	return "/" + strings.Trim(path, "/")
+}
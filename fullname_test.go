@@ -0,0 +1,36 @@
+package gojenkins
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullName(t *testing.T) {
+	assert.Equal(t, "/job/team/job/my%20service/job/feature%252Fx", JobPath("team/my service/feature%2Fx"))
+	for _, name := range []string{"deploy", "team/my service/feature%2Fx"} {
+		assert.Equal(t, name, FullNameFromPath("https://jenkins.example.com/ci"+JobPath(name)+"/42/"))
+	}
+	assert.Equal(t, "team/app", InnerJob{Url: "https://jenkins.example.com/job/team/job/app/"}.GetFullName())
+
+	var paths []string
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.EscapedPath())
+		w.Write([]byte(`{"fullName":"team/my app","url":"http://jenkins/job/team/job/my%20app/"}`))
+	})
+	defer done()
+	jenkins := &Jenkins{Server: r.Base, Requester: r}
+	job, err := jenkins.GetJob("team/my app")
+	assert.Nil(t, err)
+	assert.Equal(t, "team/my app", job.GetFullName())
+	_, err = job.GetBuild(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/job/team/job/my%20app/api/json", "/job/team/job/my%20app/3/api/json"}, paths)
+
+	for _, options := range [][]interface{}{nil, {42}, {""}} {
+		_, err = jenkins.CreateJob("<project/>", options...)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, 2, len(paths))
+}
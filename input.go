@@ -0,0 +1,99 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PendingInput is an input step a Pipeline build is waiting on.
+type PendingInput struct {
+	ID          string           `json:"id"`
+	Message     string           `json:"message"`
+	ProceedText string           `json:"proceedText"`
+	Inputs      []InputParameter `json:"inputs"`
+	ProceedURL  string           `json:"proceedUrl"`
+	AbortURL    string           `json:"abortUrl"`
+}
+
+// InputParameter is a parameter asked for by an input step.
+type InputParameter struct {
+	Type        string `json:"type"` // e.g. "BooleanParameterDefinition" or "ChoiceParameterDefinition"
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// The parameter definition, e.g. defaultVal, or choices for a choice
+	// parameter.
+	Definition map[string]interface{} `json:"definition"`
+}
+
+// GetPendingInputs returns the input steps the build is waiting on.
+func (b *Build) GetPendingInputs() ([]PendingInput, error) {
+	return b.GetPendingInputsContext(context.Background())
+}
+
+func (b *Build) GetPendingInputsContext(ctx context.Context) ([]PendingInput, error) {
+	var inputs []PendingInput
+	ar := NewAPIRequest("GET", b.Base+"/wfapi/pendingInputActions", nil)
+	ar.Suffix = ""
+	if _, err := b.Jenkins.Requester.DoContext(ctx, ar, &inputs); err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// ProceedInput approves the input step with the given id, with values for
+// its parameters.
+func (b *Build) ProceedInput(id string, params map[string]string) error {
+	return b.ProceedInputContext(context.Background(), id, params)
+}
+
+func (b *Build) ProceedInputContext(ctx context.Context, id string, params map[string]string) error {
+	if len(params) == 0 {
+		return b.postInput(ctx, id, "proceedEmpty", nil)
+	}
+	// Parameters are sent as a JSON document in the json form field.
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parameters := make([]map[string]string, len(names))
+	for i, name := range names {
+		parameters[i] = map[string]string{"name": name, "value": params[name]}
+	}
+	data := url.Values{}
+	data.Set("proceed", "Proceed")
+	data.Set("json", makeJson(map[string]interface{}{"parameter": parameters}))
+	return b.postInput(ctx, id, "proceed", data)
+}
+
+// AbortInput rejects the input step with the given id, which aborts the
+// build.
+func (b *Build) AbortInput(id string) error {
+	return b.AbortInputContext(context.Background(), id)
+}
+
+func (b *Build) AbortInputContext(ctx context.Context, id string) error {
+	return b.postInput(ctx, id, "abort", nil)
+}
+
+func (b *Build) postInput(ctx context.Context, id string, action string, data url.Values) error {
+	endpoint := b.Base + "/input/" + url.PathEscape(id) + "/" + action
+	_, err := b.Jenkins.Requester.PostContext(ctx, endpoint, strings.NewReader(data.Encode()), nil, nil)
+	return err
+}
@@ -0,0 +1,47 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingInputs(t *testing.T) {
+	var posted []string
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/crumbIssuer/api/json":
+			w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"abc"}`))
+		case "/job/a/7/wfapi/pendingInputActions/":
+			w.Write([]byte(`[{"id":"Deploy","message":"Deploy to production?","proceedText":"Ship it","inputs":[
+				{"type":"ChoiceParameterDefinition","name":"REGION","definition":{"choices":["eu","us"]}}]}]`))
+		case "/job/a/7/input/Deploy/proceed", "/job/a/7/input/Deploy/proceedEmpty", "/job/a/7/input/Deploy/abort":
+			assert.Equal(t, "abc", req.Header.Get("Jenkins-Crumb"))
+			posted = append(posted, path.Base(req.URL.Path)+" "+req.FormValue("json"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer done()
+	build := &Build{Jenkins: &Jenkins{Requester: r}, Raw: new(BuildResponse), Base: "/job/a/7"}
+
+	inputs, err := build.GetPendingInputs()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(inputs))
+	assert.Equal(t, "Ship it", inputs[0].ProceedText)
+	assert.Equal(t, "REGION", inputs[0].Inputs[0].Name)
+	assert.Equal(t, []interface{}{"eu", "us"}, inputs[0].Inputs[0].Definition["choices"])
+
+	assert.Nil(t, build.ProceedInput("Deploy", map[string]string{"REGION": "eu"}))
+	assert.Nil(t, build.ProceedInput("Deploy", nil))
+	assert.Nil(t, build.AbortInput("Deploy"))
+	assert.Equal(t, []string{
+		`proceed {"parameter":[{"name":"REGION","value":"eu"}]}`,
+		"proceedEmpty ",
+		"abort ",
+	}, posted)
+	assert.True(t, errors.Is(build.AbortInput("Missing"), ErrNotFound))
+}
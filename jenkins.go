@@ -13,27 +13,28 @@
 // under the License.
 
 // Gojenkins is a Jenkins Client in Go, that exposes the jenkins REST api in a more developer friendly way.
+//
+// Every method that talks to the Jenkins server has a variant with a Context
+// suffix, e.g. GetJob and GetJobContext. The Context variant takes a
+// context.Context as its first argument and binds all underlying HTTP requests
+// to it, so callers can cancel them or set deadlines. The plain method uses
+// context.Background().
 package gojenkins
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"log"
+	"log/slog"
 	"net/http"
-	"net/http/cookiejar"
-	"net/url"
 	"os"
-	"strconv"
+	"path"
 	"strings"
+	"sync"
 )
 
-// Basic Authentication
-type BasicAuth struct {
-	Username string
-	Password string
-}
-
 type Jenkins struct {
 	Server    string
 	Version   string
@@ -41,8 +42,10 @@ type Jenkins struct {
 	Requester *Requester
 }
 
-// Loggers
+// Loggers used by clients without a Logger of their own. Debug only receives
+// the requests and responses of clients with Requester.DebugHTTP set.
 var (
+	Debug   *log.Logger
 	Info    *log.Logger
 	Warning *log.Logger
 	Error   *log.Logger
@@ -52,6 +55,10 @@ var (
 // e.g jenkins := CreateJenkins("url").Init()
 // HTTP Client is set here, Connection to jenkins is tested here.
 func (j *Jenkins) Init() (*Jenkins, error) {
+	return j.InitContext(context.Background())
+}
+
+func (j *Jenkins) InitContext(ctx context.Context) (*Jenkins, error) {
 	j.initLoggers()
 	// Skip SSL Verification?
 	tlsCfg := &tls.Config{
@@ -62,61 +69,77 @@ func (j *Jenkins) Init() (*Jenkins, error) {
 		pool.AppendCertsFromPEM(j.Requester.CACert)
 		tlsCfg.RootCAs = pool
 	}
-	tr := &http.Transport{
-		TLSClientConfig: tlsCfg,
-	}
 
 	if j.Requester.Client == nil {
-		cookies, _ := cookiejar.New(nil)
-
-		if os.Getenv("HTTP_PROXY") != "" {
-			proxyUrl, _ := url.Parse(os.Getenv("HTTP_PROXY"))
-			tr.Proxy = http.ProxyURL(proxyUrl)
-		}
-
-		client := &http.Client{
-			Transport: tr,
-			Jar:       cookies,
-			// Fucntion to add auth on redirect.
-			CheckRedirect: j.Requester.redirectPolicyFunc,
-		}
+		tr := newTransport()
+		tr.TLSClientConfig = tlsCfg
+		j.Requester.Client = j.Requester.newHTTPClient(tr)
+	}
 
-		j.Requester.Client = client
+	if err := j.connect(ctx); err != nil {
+		return nil, err
 	}
+	return j, nil
+}
 
-	// Check Connection
+// Check Connection
+func (j *Jenkins) connect(ctx context.Context) error {
 	j.Raw = new(ExecutorResponse)
-	rsp, err := j.Requester.GetJSON("/", j.Raw, nil)
+	rsp, err := j.Requester.GetJSONContext(ctx, "/", j.Raw, nil)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	j.Version = rsp.Header.Get("X-Jenkins")
 	if j.Raw == nil {
-		return nil, errors.New("Connection Failed, Please verify that the host and credentials are correct.")
+		return errors.New("Connection Failed, Please verify that the host and credentials are correct.")
 	}
-
-	return j, nil
+	return nil
 }
 
+var initLoggersOnce sync.Once
+
+// Set up the package-level loggers, unless the client has its own Logger.
+// They are only created once and loggers assigned by the caller are kept.
 func (j *Jenkins) initLoggers() {
-	Info = log.New(os.Stdout,
-		"INFO: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
+	if j.Requester.Logger != nil {
+		return
+	}
+	initLoggersOnce.Do(func() {
+		if Debug == nil {
+			Debug = log.New(os.Stdout,
+				"DEBUG: ",
+				log.Ldate|log.Ltime|log.Lshortfile)
+		}
 
-	Warning = log.New(os.Stdout,
-		"WARNING: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
+		if Info == nil {
+			Info = log.New(os.Stdout,
+				"INFO: ",
+				log.Ldate|log.Ltime|log.Lshortfile)
+		}
+
+		if Warning == nil {
+			Warning = log.New(os.Stdout,
+				"WARNING: ",
+				log.Ldate|log.Ltime|log.Lshortfile)
+		}
 
-	Error = log.New(os.Stderr,
-		"ERROR: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
+		if Error == nil {
+			Error = log.New(os.Stderr,
+				"ERROR: ",
+				log.Ldate|log.Ltime|log.Lshortfile)
+		}
+	})
 }
 
 // Get Basic Information About Jenkins
 func (j *Jenkins) Info() (*ExecutorResponse, error) {
-	_, err := j.Requester.Get("/", j.Raw, nil)
+	return j.InfoContext(context.Background())
+}
+
+func (j *Jenkins) InfoContext(ctx context.Context) (*ExecutorResponse, error) {
+	_, err := j.Requester.GetContext(ctx, "/", j.Raw, nil)
 
 	if err != nil {
 		return nil, err
@@ -129,7 +152,11 @@ func (j *Jenkins) Info() (*ExecutorResponse, error) {
 // Example : jenkins.CreateNode("nodeName", 1, "Description", "/var/lib/jenkins", map[string]string{"method": "JNLPLauncher"})
 // By Default JNLPLauncher is created
 func (j *Jenkins) CreateNode(name string, numExecutors int, description string, remoteFS string, options ...interface{}) (*Node, error) {
-	node, _ := j.GetNode(name)
+	return j.CreateNodeContext(context.Background(), name, numExecutors, description, remoteFS, options...)
+}
+
+func (j *Jenkins) CreateNodeContext(ctx context.Context, name string, numExecutors int, description string, remoteFS string, options ...interface{}) (*Node, error) {
+	node, _ := j.GetNodeContext(ctx, name)
 
 	if node != nil {
 		return node, nil
@@ -191,35 +218,42 @@ func (j *Jenkins) CreateNode(name string, numExecutors int, description string,
 		}),
 	}
 
-	resp, err := j.Requester.Post("/computer/doCreateItem", nil, nil, qr)
+	resp, err := j.Requester.PostContext(ctx, "/computer/doCreateItem", nil, nil, qr)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode < 400 {
-		_, err := node.Poll()
+		_, err := node.PollContext(ctx)
 		if err != nil {
 			return nil, err
 		}
 		return node, nil
 	}
-	return nil, errors.New(strconv.Itoa(resp.StatusCode))
+	return nil, newAPIError(resp)
 }
 
 // Create a new job from config File
 // Method takes XML string as first parameter, and if the name is not specified in the config file
 // takes name as string as second parameter
 // e.g jenkins.CreateJob("<config></config>","newJobName")
+// The name may be a full name, to create the job in an existing folder: "team/service/newJobName".
 func (j *Jenkins) CreateJob(config string, options ...interface{}) (*Job, error) {
-	qr := make(map[string]string)
+	return j.CreateJobContext(context.Background(), config, options...)
+}
+
+func (j *Jenkins) CreateJobContext(ctx context.Context, config string, options ...interface{}) (*Job, error) {
+	var name string
 	if len(options) > 0 {
-		qr["name"] = options[0].(string)
-	} else {
+		name, _ = options[0].(string)
+	}
+	if name == "" {
 		return nil, errors.New("Error Creating Job, job name is missing")
 	}
-	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + qr["name"]}
-	job, err := jobObj.Create(config, qr)
+	qr := map[string]string{"name": path.Base(name)}
+	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: JobPath(name)}
+	job, err := jobObj.CreateContext(ctx, config, qr)
 	if err != nil {
 		return nil, err
 	}
@@ -227,71 +261,101 @@ func (j *Jenkins) CreateJob(config string, options ...interface{}) (*Job, error)
 }
 
 // Rename a job.
-// First parameter job old name, Second parameter job new name.
+// First parameter full name of the job, Second parameter job new name within the same folder.
 func (j *Jenkins) RenameJob(job string, name string) *Job {
-	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + job}
-	jobObj.Rename(name)
+	return j.RenameJobContext(context.Background(), job, name)
+}
+
+func (j *Jenkins) RenameJobContext(ctx context.Context, job string, name string) *Job {
+	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: JobPath(job)}
+	jobObj.RenameContext(ctx, name)
 	return &jobObj
 }
 
 // Create a copy of a job.
-// First parameter Name of the job to copy from, Second parameter new job name.
+// First parameter full name of the job to copy from, Second parameter new job name.
+// The copy is created in the same folder.
 func (j *Jenkins) CopyJob(copyFrom string, newName string) (*Job, error) {
-	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + copyFrom}
-	_, err := job.Poll()
+	return j.CopyJobContext(context.Background(), copyFrom, newName)
+}
+
+func (j *Jenkins) CopyJobContext(ctx context.Context, copyFrom string, newName string) (*Job, error) {
+	job := Job{Jenkins: j, Raw: new(JobResponse), Base: JobPath(copyFrom)}
+	_, err := job.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return job.Copy(newName)
+	return job.CopyContext(ctx, newName)
 }
 
-// Delete a job.
+// Delete a job, given its full name.
 func (j *Jenkins) DeleteJob(name string) (bool, error) {
-	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + name}
-	return job.Delete()
+	return j.DeleteJobContext(context.Background(), name)
+}
+
+func (j *Jenkins) DeleteJobContext(ctx context.Context, name string) (bool, error) {
+	job := Job{Jenkins: j, Raw: new(JobResponse), Base: JobPath(name)}
+	return job.DeleteContext(ctx)
 }
 
 // Invoke a job.
-// First parameter job name, second parameter is optional Build parameters.
+// First parameter full name of the job, second parameter is optional Build parameters.
+// Returns the id of the queue item, see Job.InvokeSimple.
 func (j *Jenkins) BuildJob(name string, options ...interface{}) (int64, error) {
-	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + name}
+	return j.BuildJobContext(context.Background(), name, options...)
+}
+
+func (j *Jenkins) BuildJobContext(ctx context.Context, name string, options ...interface{}) (int64, error) {
+	job := Job{Jenkins: j, Raw: new(JobResponse), Base: JobPath(name)}
 	var params map[string]string
 	if len(options) > 0 {
 		params, _ = options[0].(map[string]string)
 	}
-	return job.InvokeSimple(params)
+	return job.InvokeSimpleContext(ctx, params)
 }
 
-func (j *Jenkins) GetNode(name string) (*Node, error) {
+func (j *Jenkins) GetNode(name string, options ...interface{}) (*Node, error) {
+	return j.GetNodeContext(context.Background(), name, options...)
+}
+
+func (j *Jenkins) GetNodeContext(ctx context.Context, name string, options ...interface{}) (*Node, error) {
 	node := Node{Jenkins: j, Raw: new(NodeResponse), Base: "/computer/" + name}
-	status, err := node.Poll()
+	status, err := node.PollContext(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
 	if status == 200 {
 		return &node, nil
 	}
-	return nil, errors.New("No node found")
+	return nil, j.Requester.statusError("GET", node.Base, status)
+}
+
+func (j *Jenkins) GetLabel(name string, options ...interface{}) (*Label, error) {
+	return j.GetLabelContext(context.Background(), name, options...)
 }
 
-func (j *Jenkins) GetLabel(name string) (*Label, error) {
+func (j *Jenkins) GetLabelContext(ctx context.Context, name string, options ...interface{}) (*Label, error) {
 	label := Label{Jenkins: j, Raw: new(LabelResponse), Base: "/label/" + name}
-	status, err := label.Poll()
+	status, err := label.PollContext(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
 	if status == 200 {
 		return &label, nil
 	}
-	return nil, errors.New("No label found")
+	return nil, j.Requester.statusError("GET", label.Base, status)
+}
+
+func (j *Jenkins) GetBuild(jobName string, number int64, options ...interface{}) (*Build, error) {
+	return j.GetBuildContext(context.Background(), jobName, number, options...)
 }
 
-func (j *Jenkins) GetBuild(jobName string, number int64) (*Build, error) {
-	job, err := j.GetJob(jobName)
+func (j *Jenkins) GetBuildContext(ctx context.Context, jobName string, number int64, options ...interface{}) (*Build, error) {
+	job, err := j.GetJobContext(ctx, jobName)
 	if err != nil {
 		return nil, err
 	}
-	build, err := job.GetBuild(number)
+	build, err := job.GetBuildContext(ctx, number, options...)
 
 	if err != nil {
 		return nil, err
@@ -299,26 +363,36 @@ func (j *Jenkins) GetBuild(jobName string, number int64) (*Build, error) {
 	return build, nil
 }
 
+// Get a job by its full name, e.g. "team/service/deploy". The names of the
+// enclosing folders may also be passed separately: GetJob("deploy", "team", "service").
 func (j *Jenkins) GetJob(id string, parentIDs ...string) (*Job, error) {
-	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + strings.Join(append(parentIDs, id), "/job/")}
-	status, err := job.Poll()
+	return j.GetJobContext(context.Background(), id, parentIDs...)
+}
+
+func (j *Jenkins) GetJobContext(ctx context.Context, id string, parentIDs ...string) (*Job, error) {
+	job := Job{Jenkins: j, Raw: new(JobResponse), Base: JobPath(strings.Join(append(parentIDs, id), "/"))}
+	status, err := job.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if status == 200 {
 		return &job, nil
 	}
-	return nil, errors.New(strconv.Itoa(status))
+	return nil, j.Requester.statusError("GET", job.Base, status)
 }
 
 func (j *Jenkins) GetAllNodes() ([]*Node, error) {
+	return j.GetAllNodesContext(context.Background())
+}
+
+func (j *Jenkins) GetAllNodesContext(ctx context.Context) ([]*Node, error) {
 	computers := new(Computers)
 
 	qr := map[string]string{
 		"depth": "1",
 	}
 
-	_, err := j.Requester.GetJSON("/computer", computers, qr)
+	_, err := j.Requester.GetJSONContext(ctx, "/computer", computers, qr)
 	if err != nil {
 		return nil, err
 	}
@@ -336,18 +410,26 @@ func (j *Jenkins) GetAllNodes() ([]*Node, error) {
 // To get all the other info of the build use jenkins.GetBuild(job,buildNumber)
 // or job.GetBuild(buildNumber)
 func (j *Jenkins) GetAllBuildIds(job string) ([]JobBuild, error) {
-	jobObj, err := j.GetJob(job)
+	return j.GetAllBuildIdsContext(context.Background(), job)
+}
+
+func (j *Jenkins) GetAllBuildIdsContext(ctx context.Context, job string) ([]JobBuild, error) {
+	jobObj, err := j.GetJobContext(ctx, job)
 	if err != nil {
 		return nil, err
 	}
-	return jobObj.GetAllBuildIds()
+	return jobObj.GetAllBuildIdsContext(ctx)
 }
 
 // Get Only Array of Job Names, Color, URL
 // Does not query each single Job.
 func (j *Jenkins) GetAllJobNames() ([]InnerJob, error) {
+	return j.GetAllJobNamesContext(context.Background())
+}
+
+func (j *Jenkins) GetAllJobNamesContext(ctx context.Context) ([]InnerJob, error) {
 	exec := Executor{Raw: new(ExecutorResponse), Jenkins: j}
-	_, err := j.Requester.GetJSON("/", exec.Raw, nil)
+	_, err := j.Requester.GetJSONContext(ctx, "/", exec.Raw, nil)
 
 	if err != nil {
 		return nil, err
@@ -359,8 +441,12 @@ func (j *Jenkins) GetAllJobNames() ([]InnerJob, error) {
 // Get All Possible Job Objects.
 // Each job will be queried.
 func (j *Jenkins) GetAllJobs() ([]*Job, error) {
+	return j.GetAllJobsContext(context.Background())
+}
+
+func (j *Jenkins) GetAllJobsContext(ctx context.Context) ([]*Job, error) {
 	exec := Executor{Raw: new(ExecutorResponse), Jenkins: j}
-	_, err := j.Requester.GetJSON("/", exec.Raw, nil)
+	_, err := j.Requester.GetJSONContext(ctx, "/", exec.Raw, nil)
 
 	if err != nil {
 		return nil, err
@@ -368,7 +454,7 @@ func (j *Jenkins) GetAllJobs() ([]*Job, error) {
 
 	jobs := make([]*Job, len(exec.Raw.Jobs))
 	for i, job := range exec.Raw.Jobs {
-		ji, err := j.GetJob(job.Name)
+		ji, err := j.GetJobContext(ctx, job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -378,9 +464,13 @@ func (j *Jenkins) GetAllJobs() ([]*Job, error) {
 }
 
 // Returns a Queue
-func (j *Jenkins) GetQueue() (*Queue, error) {
+func (j *Jenkins) GetQueue(options ...interface{}) (*Queue, error) {
+	return j.GetQueueContext(context.Background(), options...)
+}
+
+func (j *Jenkins) GetQueueContext(ctx context.Context, options ...interface{}) (*Queue, error) {
 	q := &Queue{Jenkins: j, Raw: new(queueResponse), Base: j.GetQueueUrl()}
-	_, err := q.Poll()
+	_, err := q.PollContext(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -393,15 +483,23 @@ func (j *Jenkins) GetQueueUrl() string {
 
 // Get Artifact data by Hash
 func (j *Jenkins) GetArtifactData(id string) (*fingerPrintResponse, error) {
+	return j.GetArtifactDataContext(context.Background(), id)
+}
+
+func (j *Jenkins) GetArtifactDataContext(ctx context.Context, id string) (*fingerPrintResponse, error) {
 	fp := Fingerprint{Jenkins: j, Base: "/fingerprint/", Id: id, Raw: new(fingerPrintResponse)}
-	return fp.GetInfo()
+	return fp.GetInfoContext(ctx)
 }
 
 // Returns the list of all plugins installed on the Jenkins server.
 // You can supply depth parameter, to limit how much data is returned.
 func (j *Jenkins) GetPlugins(depth int) (*Plugins, error) {
+	return j.GetPluginsContext(context.Background(), depth)
+}
+
+func (j *Jenkins) GetPluginsContext(ctx context.Context, depth int) (*Plugins, error) {
 	p := Plugins{Jenkins: j, Raw: new(PluginResponse), Base: "/pluginManager", Depth: depth}
-	_, err := p.Poll()
+	_, err := p.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -411,7 +509,11 @@ func (j *Jenkins) GetPlugins(depth int) (*Plugins, error) {
 // Check if the plugin is installed on the server.
 // Depth level 1 is used. If you need to go deeper, you can use GetPlugins, and iterate through them.
 func (j *Jenkins) HasPlugin(name string) (*Plugin, error) {
-	p, err := j.GetPlugins(1)
+	return j.HasPluginContext(context.Background(), name)
+}
+
+func (j *Jenkins) HasPluginContext(ctx context.Context, name string) (*Plugin, error) {
+	p, err := j.GetPluginsContext(ctx, 1)
 
 	if err != nil {
 		return nil, err
@@ -421,8 +523,12 @@ func (j *Jenkins) HasPlugin(name string) (*Plugin, error) {
 
 // Verify Fingerprint
 func (j *Jenkins) ValidateFingerPrint(id string) (bool, error) {
+	return j.ValidateFingerPrintContext(context.Background(), id)
+}
+
+func (j *Jenkins) ValidateFingerPrintContext(ctx context.Context, id string) (bool, error) {
 	fp := Fingerprint{Jenkins: j, Base: "/fingerprint/", Id: id, Raw: new(fingerPrintResponse)}
-	valid, err := fp.Valid()
+	valid, err := fp.ValidContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -432,10 +538,14 @@ func (j *Jenkins) ValidateFingerPrint(id string) (bool, error) {
 	return false, nil
 }
 
-func (j *Jenkins) GetView(name string) (*View, error) {
+func (j *Jenkins) GetView(name string, options ...interface{}) (*View, error) {
+	return j.GetViewContext(context.Background(), name, options...)
+}
+
+func (j *Jenkins) GetViewContext(ctx context.Context, name string, options ...interface{}) (*View, error) {
 	url := "/view/" + name
 	view := View{Jenkins: j, Raw: new(ViewResponse), Base: url}
-	_, err := view.Poll()
+	_, err := view.PollContext(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -443,13 +553,17 @@ func (j *Jenkins) GetView(name string) (*View, error) {
 }
 
 func (j *Jenkins) GetAllViews() ([]*View, error) {
-	_, err := j.Poll()
+	return j.GetAllViewsContext(context.Background())
+}
+
+func (j *Jenkins) GetAllViewsContext(ctx context.Context) ([]*View, error) {
+	_, err := j.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	views := make([]*View, len(j.Raw.Views))
 	for i, v := range j.Raw.Views {
-		views[i], _ = j.GetView(v.Name)
+		views[i], _ = j.GetViewContext(ctx, v.Name)
 	}
 	return views, nil
 }
@@ -465,14 +579,17 @@ func (j *Jenkins) GetAllViews() ([]*View, error) {
 // 		gojenkins.PIPELINE_VIEW
 // Example: jenkins.CreateView("newView",gojenkins.LIST_VIEW)
 func (j *Jenkins) CreateView(name string, viewType string) (*View, error) {
-	exists, err := j.GetView(name)
-	if err != nil {
-		return nil, err
-	}
-	if exists.Raw.Name != "" {
+	return j.CreateViewContext(context.Background(), name, viewType)
+}
+
+func (j *Jenkins) CreateViewContext(ctx context.Context, name string, viewType string) (*View, error) {
+	exists, err := j.GetViewContext(ctx, name)
+	if err == nil {
 		return exists, errors.New("View already exists")
 	}
-	view := &View{Jenkins: j, Raw: new(ViewResponse), Base: "/view/" + name}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
 	endpoint := "/createView"
 	data := map[string]string{
 		"name":   name,
@@ -483,20 +600,26 @@ func (j *Jenkins) CreateView(name string, viewType string) (*View, error) {
 			"mode": viewType,
 		}),
 	}
-	r, err := j.Requester.Post(endpoint, nil, view.Raw, data)
+	r, err := j.Requester.PostContext(ctx, endpoint, nil, nil, data)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if r.StatusCode == 200 {
-		return j.GetView(name)
+		return j.GetViewContext(ctx, name)
 	}
-	return nil, errors.New(strconv.Itoa(r.StatusCode))
+	return nil, newAPIError(r)
 }
 
-func (j *Jenkins) Poll() (int, error) {
-	resp, err := j.Requester.GetJSON("/", j.Raw, nil)
+// Poll for current data. Optional parameters - a *Tree selecting the fields
+// to fetch, or the depth as int.
+func (j *Jenkins) Poll(options ...interface{}) (int, error) {
+	return j.PollContext(context.Background(), options...)
+}
+
+func (j *Jenkins) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	resp, err := j.Requester.GetJSONContext(ctx, "/", j.Raw, pollQuery(options))
 	if err != nil {
 		return 0, err
 	}
@@ -504,8 +627,10 @@ func (j *Jenkins) Poll() (int, error) {
 }
 
 // Creates a new Jenkins Instance
-// Optional parameters are: username, password
+// Optional parameters are: username, password or a single Authenticator
 // After creating an instance call init method.
+//
+// Deprecated: Use NewClient, which configures the HTTP client through options.
 func CreateJenkins(base string, auth ...interface{}) *Jenkins {
 	j := &Jenkins{}
 	if strings.HasSuffix(base, "/") {
@@ -514,7 +639,68 @@ func CreateJenkins(base string, auth ...interface{}) *Jenkins {
 	j.Server = base
 	j.Requester = &Requester{Base: base, SslVerify: true}
 	if len(auth) == 2 {
-		j.Requester.BasicAuth = &BasicAuth{Username: auth[0].(string), Password: auth[1].(string)}
+		username, _ := auth[0].(string)
+		password, _ := auth[1].(string)
+		j.Requester.BasicAuth = &BasicAuth{Username: username, Password: password}
+	}
+	if len(auth) == 1 {
+		j.Requester.Auth, _ = auth[0].(Authenticator)
 	}
 	return j
 }
+
+// Creates a new Jenkins client and checks the connection to the server.
+// e.g. jenkins, err := NewClient("https://jenkins.example.com", WithAuth(&APITokenAuth{"admin", "token"}))
+// Without options, the client uses the proxy from the environment, sane
+// timeouts for dialing and the TLS handshake, and keeps cookies.
+func NewClient(base string, opts ...Option) (*Jenkins, error) {
+	return NewClientContext(context.Background(), base, opts...)
+}
+
+func NewClientContext(ctx context.Context, base string, opts ...Option) (*Jenkins, error) {
+	cfg := &clientConfig{proxy: http.ProxyFromEnvironment}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	// Debug logging needs a logger that does not drop debug messages.
+	if cfg.debugHTTP && cfg.logger == nil {
+		cfg.logger = NewStdLogger(log.New(os.Stderr, "", log.LstdFlags), slog.LevelDebug)
+	}
+	limiter, err := cfg.newLimiter()
+	if err != nil {
+		return nil, err
+	}
+
+	base = strings.TrimSuffix(base, "/")
+	j := &Jenkins{Server: base, Raw: new(ExecutorResponse)}
+	j.Requester = &Requester{
+		Base:       base,
+		Auth:       cfg.auth,
+		SslVerify:  cfg.tlsConfig == nil || !cfg.tlsConfig.InsecureSkipVerify,
+		Retry:      cfg.retry,
+		UserAgent:  cfg.userAgent,
+		Logger:     cfg.logger,
+		DebugHTTP:  cfg.debugHTTP,
+		Middleware: cfg.middleware,
+		Limiter:    limiter,
+		Cache:      cfg.cache,
+	}
+	j.Requester.Client = cfg.httpClient
+	if j.Requester.Client == nil {
+		transport := cfg.transport
+		if transport == nil {
+			transport = cfg.newTransport()
+		}
+		j.Requester.Client = j.Requester.newHTTPClient(transport)
+		j.Requester.Client.Timeout = cfg.timeout
+	}
+
+	if !cfg.skipConnectivityCheck {
+		if err := j.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
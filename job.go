@@ -16,11 +16,11 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"net/url"
-	"path"
 	"strconv"
 	"strings"
 )
@@ -63,6 +63,7 @@ type JobResponse struct {
 	DisplayNameOrNull  interface{} `json:"displayNameOrNull"`
 	DownstreamProjects []InnerJob  `json:"downstreamProjects"`
 	FirstBuild         JobBuild
+	FullName           string `json:"fullName"`
 	HealthReport       []struct {
 		Description   string `json:"description"`
 		IconClassName string `json:"iconClassName"`
@@ -114,19 +115,24 @@ func (j *Job) GetDetails() *JobResponse {
 	return j.Raw
 }
 
-func (j *Job) GetBuild(id int64) (*Build, error) {
-	build := Build{Jenkins: j.Jenkins, Job: j, Raw: new(BuildResponse), Depth: 1, Base: "/job/" + j.GetName() + "/" + strconv.FormatInt(id, 10)}
-	status, err := build.Poll()
+// Optional parameters are passed on to Build.Poll.
+func (j *Job) GetBuild(id int64, options ...interface{}) (*Build, error) {
+	return j.GetBuildContext(context.Background(), id, options...)
+}
+
+func (j *Job) GetBuildContext(ctx context.Context, id int64, options ...interface{}) (*Build, error) {
+	build := Build{Jenkins: j.Jenkins, Job: j, Raw: new(BuildResponse), Depth: 1, Base: j.Base + "/" + strconv.FormatInt(id, 10)}
+	status, err := build.PollContext(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
 	if status == 200 {
 		return &build, nil
 	}
-	return nil, errors.New(strconv.Itoa(status))
+	return nil, j.Jenkins.Requester.statusError("GET", build.Base, status)
 }
 
-func (j *Job) getBuildByType(buildType string) (*Build, error) {
+func (j *Job) getBuildByType(ctx context.Context, buildType string) (*Build, error) {
 	allowed := map[string]JobBuild{
 		"lastStableBuild":     j.Raw.LastStableBuild,
 		"lastSuccessfulBuild": j.Raw.LastSuccessfulBuild,
@@ -147,46 +153,74 @@ func (j *Job) getBuildByType(buildType string) (*Build, error) {
 		Job:     j,
 		Raw:     new(BuildResponse),
 		Base:    j.Base + "/" + number}
-	status, err := build.Poll()
+	status, err := build.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if status == 200 {
 		return &build, nil
 	}
-	return nil, errors.New(strconv.Itoa(status))
+	return nil, j.Jenkins.Requester.statusError("GET", build.Base, status)
 }
 
 func (j *Job) GetLastSuccessfulBuild() (*Build, error) {
-	return j.getBuildByType("lastSuccessfulBuild")
+	return j.GetLastSuccessfulBuildContext(context.Background())
+}
+
+func (j *Job) GetLastSuccessfulBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastSuccessfulBuild")
 }
 
 func (j *Job) GetFirstBuild() (*Build, error) {
-	return j.getBuildByType("firstBuild")
+	return j.GetFirstBuildContext(context.Background())
+}
+
+func (j *Job) GetFirstBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "firstBuild")
 }
 
 func (j *Job) GetLastBuild() (*Build, error) {
-	return j.getBuildByType("lastBuild")
+	return j.GetLastBuildContext(context.Background())
+}
+
+func (j *Job) GetLastBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastBuild")
 }
 
 func (j *Job) GetLastStableBuild() (*Build, error) {
-	return j.getBuildByType("lastStableBuild")
+	return j.GetLastStableBuildContext(context.Background())
+}
+
+func (j *Job) GetLastStableBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastStableBuild")
 }
 
 func (j *Job) GetLastFailedBuild() (*Build, error) {
-	return j.getBuildByType("lastFailedBuild")
+	return j.GetLastFailedBuildContext(context.Background())
+}
+
+func (j *Job) GetLastFailedBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastFailedBuild")
 }
 
 func (j *Job) GetLastCompletedBuild() (*Build, error) {
-	return j.getBuildByType("lastCompletedBuild")
+	return j.GetLastCompletedBuildContext(context.Background())
+}
+
+func (j *Job) GetLastCompletedBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastCompletedBuild")
 }
 
 // Returns All Builds with Number and URL
 func (j *Job) GetAllBuildIds() ([]JobBuild, error) {
+	return j.GetAllBuildIdsContext(context.Background())
+}
+
+func (j *Job) GetAllBuildIdsContext(ctx context.Context) ([]JobBuild, error) {
 	var buildsResp struct {
 		Builds []JobBuild `json:"allBuilds"`
 	}
-	_, err := j.Jenkins.Requester.GetJSON(j.Base, &buildsResp, map[string]string{"tree": "allBuilds[number,url]"})
+	_, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, &buildsResp, map[string]string{"tree": "allBuilds[number,url]"})
 	if err != nil {
 		return nil, err
 	}
@@ -206,9 +240,13 @@ func (j *Job) GetInnerJobsMetadata() []InnerJob {
 }
 
 func (j *Job) GetUpstreamJobs() ([]*Job, error) {
+	return j.GetUpstreamJobsContext(context.Background())
+}
+
+func (j *Job) GetUpstreamJobsContext(ctx context.Context) ([]*Job, error) {
 	jobs := make([]*Job, len(j.Raw.UpstreamProjects))
 	for i, job := range j.Raw.UpstreamProjects {
-		ji, err := j.Jenkins.GetJob(job.Name)
+		ji, err := j.Jenkins.GetJobContext(ctx, job.GetFullName())
 		if err != nil {
 			return nil, err
 		}
@@ -218,9 +256,13 @@ func (j *Job) GetUpstreamJobs() ([]*Job, error) {
 }
 
 func (j *Job) GetDownstreamJobs() ([]*Job, error) {
+	return j.GetDownstreamJobsContext(context.Background())
+}
+
+func (j *Job) GetDownstreamJobsContext(ctx context.Context) ([]*Job, error) {
 	jobs := make([]*Job, len(j.Raw.DownstreamProjects))
 	for i, job := range j.Raw.DownstreamProjects {
-		ji, err := j.Jenkins.GetJob(job.Name)
+		ji, err := j.Jenkins.GetJobContext(ctx, job.GetFullName())
 		if err != nil {
 			return nil, err
 		}
@@ -230,21 +272,29 @@ func (j *Job) GetDownstreamJobs() ([]*Job, error) {
 }
 
 func (j *Job) GetInnerJob(id string) (*Job, error) {
-	job := Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: j.Base + "/job/" + id}
-	status, err := job.Poll()
+	return j.GetInnerJobContext(context.Background(), id)
+}
+
+func (j *Job) GetInnerJobContext(ctx context.Context, id string) (*Job, error) {
+	job := Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: j.Base + JobPath(id)}
+	status, err := job.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if status == 200 {
 		return &job, nil
 	}
-	return nil, errors.New(strconv.Itoa(status))
+	return nil, j.Jenkins.Requester.statusError("GET", job.Base, status)
 }
 
 func (j *Job) GetInnerJobs() ([]*Job, error) {
+	return j.GetInnerJobsContext(context.Background())
+}
+
+func (j *Job) GetInnerJobsContext(ctx context.Context) ([]*Job, error) {
 	jobs := make([]*Job, len(j.Raw.Jobs))
 	for i, job := range j.Raw.Jobs {
-		ji, err := j.GetInnerJob(job.Name)
+		ji, err := j.GetInnerJobContext(ctx, job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -254,42 +304,58 @@ func (j *Job) GetInnerJobs() ([]*Job, error) {
 }
 
 func (j *Job) Enable() (bool, error) {
-	resp, err := j.Jenkins.Requester.Post(j.Base+"/enable", nil, nil, nil)
+	return j.EnableContext(context.Background())
+}
+
+func (j *Job) EnableContext(ctx context.Context) (bool, error) {
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/enable", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
 	if resp.StatusCode != 200 {
-		return false, errors.New(strconv.Itoa(resp.StatusCode))
+		return false, newAPIError(resp)
 	}
 	return true, nil
 }
 
 func (j *Job) Disable() (bool, error) {
-	resp, err := j.Jenkins.Requester.Post(j.Base+"/disable", nil, nil, nil)
+	return j.DisableContext(context.Background())
+}
+
+func (j *Job) DisableContext(ctx context.Context) (bool, error) {
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/disable", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
 	if resp.StatusCode != 200 {
-		return false, errors.New(strconv.Itoa(resp.StatusCode))
+		return false, newAPIError(resp)
 	}
 	return true, nil
 }
 
 func (j *Job) Delete() (bool, error) {
-	resp, err := j.Jenkins.Requester.Post(j.Base+"/doDelete", nil, nil, nil)
+	return j.DeleteContext(context.Background())
+}
+
+func (j *Job) DeleteContext(ctx context.Context) (bool, error) {
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/doDelete", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
 	if resp.StatusCode != 200 {
-		return false, errors.New(strconv.Itoa(resp.StatusCode))
+		return false, newAPIError(resp)
 	}
 	return true, nil
 }
 
 func (j *Job) Rename(name string) (bool, error) {
+	return j.RenameContext(context.Background(), name)
+}
+
+func (j *Job) RenameContext(ctx context.Context, name string) (bool, error) {
 	data := url.Values{}
 	data.Set("newName", name)
-	_, err := j.Jenkins.Requester.Post(j.Base+"/doRename", bytes.NewBufferString(data.Encode()), nil, nil)
+	_, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/doRename", bytes.NewBufferString(data.Encode()), nil, nil)
 	if err != nil {
 		return false, err
 	}
@@ -297,65 +363,99 @@ func (j *Job) Rename(name string) (bool, error) {
 }
 
 func (j *Job) Create(config string, qr ...interface{}) (*Job, error) {
+	return j.CreateContext(context.Background(), config, qr...)
+}
+
+func (j *Job) CreateContext(ctx context.Context, config string, qr ...interface{}) (*Job, error) {
 	var querystring map[string]string
 	if len(qr) > 0 {
 		querystring = qr[0].(map[string]string)
 	}
-	resp, err := j.Jenkins.Requester.PostXML(j.parentBase()+"/createItem", config, j.Raw, querystring)
+	resp, err := j.Jenkins.Requester.PostXMLContext(ctx, j.parentBase()+"/createItem", config, nil, querystring)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode == 200 {
-		j.Poll()
+		j.PollContext(ctx)
 		return j, nil
 	}
-	return nil, errors.New(strconv.Itoa(resp.StatusCode))
+	return nil, newAPIError(resp)
 }
 
 func (j *Job) Copy(destinationName string) (*Job, error) {
+	return j.CopyContext(context.Background(), destinationName)
+}
+
+func (j *Job) CopyContext(ctx context.Context, destinationName string) (*Job, error) {
 	qr := map[string]string{"name": destinationName, "from": j.GetName(), "mode": "copy"}
-	resp, err := j.Jenkins.Requester.Post(j.parentBase()+"/createItem", nil, nil, qr)
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.parentBase()+"/createItem", nil, nil, qr)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode == 200 {
-		newJob := &Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: "/job/" + destinationName}
-		_, err := newJob.Poll()
+		newJob := &Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: j.parentBase() + JobPath(destinationName)}
+		_, err := newJob.PollContext(ctx)
 		if err != nil {
 			return nil, err
 		}
 		return newJob, nil
 	}
-	return nil, errors.New(strconv.Itoa(resp.StatusCode))
+	return nil, newAPIError(resp)
 }
 
 func (j *Job) UpdateConfig(config string) error {
+	return j.UpdateConfigContext(context.Background(), config)
+}
+
+func (j *Job) UpdateConfigContext(ctx context.Context, config string) error {
 
 	var querystring map[string]string
 
-	resp, err := j.Jenkins.Requester.PostXML(j.Base+"/config.xml", config, nil, querystring)
+	resp, err := j.Jenkins.Requester.PostXMLContext(ctx, j.Base+"/config.xml", config, nil, querystring)
 	if err != nil {
 		return err
 	}
 	if resp.StatusCode == 200 {
-		j.Poll()
+		j.PollContext(ctx)
 		return nil
 	}
-	return errors.New(strconv.Itoa(resp.StatusCode))
+	return newAPIError(resp)
 
 }
 
 func (j *Job) GetConfig() (string, error) {
+	return j.GetConfigContext(context.Background())
+}
+
+func (j *Job) GetConfigContext(ctx context.Context) (string, error) {
 	var data string
-	_, err := j.Jenkins.Requester.GetXML(j.Base+"/config.xml", &data, nil)
+	_, err := j.Jenkins.Requester.GetXMLContext(ctx, j.Base+"/config.xml", &data, nil)
 	if err != nil {
 		return "", err
 	}
 	return data, nil
 }
 
+// Open config.xml for reading, optionally only a range of bytes.
+// The caller must close the reader.
+func (j *Job) ConfigReader(rng ...ByteRange) (io.ReadCloser, error) {
+	return j.ConfigReaderContext(context.Background(), rng...)
+}
+
+func (j *Job) ConfigReaderContext(ctx context.Context, rng ...ByteRange) (io.ReadCloser, error) {
+	response, err := j.Jenkins.Requester.GetStreamContext(ctx, j.Base+"/config.xml", nil, rng...)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
 func (j *Job) GetParameters() ([]ParameterDefinition, error) {
-	_, err := j.Poll()
+	return j.GetParametersContext(context.Background())
+}
+
+func (j *Job) GetParametersContext(ctx context.Context) ([]ParameterDefinition, error) {
+	_, err := j.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -369,25 +469,37 @@ func (j *Job) GetParameters() ([]ParameterDefinition, error) {
 }
 
 func (j *Job) IsQueued() (bool, error) {
-	if _, err := j.Poll(); err != nil {
+	return j.IsQueuedContext(context.Background())
+}
+
+func (j *Job) IsQueuedContext(ctx context.Context) (bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
 		return false, err
 	}
 	return j.Raw.InQueue, nil
 }
 
 func (j *Job) IsRunning() (bool, error) {
-	if _, err := j.Poll(); err != nil {
+	return j.IsRunningContext(context.Background())
+}
+
+func (j *Job) IsRunningContext(ctx context.Context) (bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
 		return false, err
 	}
-	lastBuild, err := j.GetLastBuild()
+	lastBuild, err := j.GetLastBuildContext(ctx)
 	if err != nil {
 		return false, err
 	}
-	return lastBuild.IsRunning(), nil
+	return lastBuild.IsRunningContext(ctx), nil
 }
 
 func (j *Job) IsEnabled() (bool, error) {
-	if _, err := j.Poll(); err != nil {
+	return j.IsEnabledContext(context.Background())
+}
+
+func (j *Job) IsEnabledContext(ctx context.Context) (bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
 		return false, err
 	}
 	return j.Raw.Color != "disabled", nil
@@ -397,18 +509,25 @@ func (j *Job) HasQueuedBuild() {
 	panic("Not Implemented yet")
 }
 
+// InvokeSimple triggers a build unless the job is queued already. The returned
+// number is the id of the queue item, not the build number; use Trigger to
+// follow the item to its build.
 func (j *Job) InvokeSimple(params map[string]string) (int64, error) {
-	isQueued, err := j.IsQueued()
+	return j.InvokeSimpleContext(context.Background(), params)
+}
+
+func (j *Job) InvokeSimpleContext(ctx context.Context, params map[string]string) (int64, error) {
+	isQueued, err := j.IsQueuedContext(ctx)
 	if err != nil {
 		return 0, err
 	}
 	if isQueued {
-		Error.Printf("%s is already running", j.GetName())
+		j.Jenkins.Requester.logger().Error("Job is already running", "job", j.GetName())
 		return 0, nil
 	}
 
 	endpoint := "/build"
-	parameters, err := j.GetParameters()
+	parameters, err := j.GetParametersContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -419,43 +538,28 @@ func (j *Job) InvokeSimple(params map[string]string) (int64, error) {
 	for k, v := range params {
 		data.Set(k, v)
 	}
-	resp, err := j.Jenkins.Requester.Post(j.Base+endpoint, bytes.NewBufferString(data.Encode()), nil, nil)
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.Base+endpoint, bytes.NewBufferString(data.Encode()), nil, nil)
 	if err != nil {
 		return 0, err
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		return 0, errors.New("Could not invoke job " + j.GetName())
-	}
-
-	location := resp.Header.Get("Location")
-	if location == "" {
-		return 0, errors.New("Don't have key \"Location\" in response of header")
-	}
-
-	u, err := url.Parse(location)
-	if err != nil {
-		return 0, err
-	}
-
-	number, err := strconv.ParseInt(path.Base(u.Path), 10, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	return number, nil
+	return queueItemID(resp)
 }
 
 func (j *Job) Invoke(files []string, skipIfRunning bool, params map[string]string, cause string, securityToken string) (bool, error) {
-	isQueued, err := j.IsQueued()
+	return j.InvokeContext(context.Background(), files, skipIfRunning, params, cause, securityToken)
+}
+
+func (j *Job) InvokeContext(ctx context.Context, files []string, skipIfRunning bool, params map[string]string, cause string, securityToken string) (bool, error) {
+	isQueued, err := j.IsQueuedContext(ctx)
 	if err != nil {
 		return false, err
 	}
 	if isQueued {
-		Error.Printf("%s is already running", j.GetName())
+		j.Jenkins.Requester.logger().Error("Job is already running", "job", j.GetName())
 		return false, nil
 	}
-	isRunning, err := j.IsRunning()
+	isRunning, err := j.IsRunningContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -484,18 +588,24 @@ func (j *Job) Invoke(files []string, skipIfRunning bool, params map[string]strin
 
 	buildParams["json"] = string(makeJson(params))
 	b, _ := json.Marshal(buildParams)
-	resp, err := j.Jenkins.Requester.PostFiles(j.Base+base, bytes.NewBuffer(b), nil, reqParams, files)
+	resp, err := j.Jenkins.Requester.PostFilesContext(ctx, j.Base+base, bytes.NewBuffer(b), nil, reqParams, files)
 	if err != nil {
 		return false, err
 	}
 	if resp.StatusCode == 200 || resp.StatusCode == 201 {
 		return true, nil
 	}
-	return false, errors.New(strconv.Itoa(resp.StatusCode))
+	return false, newAPIError(resp)
 }
 
-func (j *Job) Poll() (int, error) {
-	response, err := j.Jenkins.Requester.GetJSON(j.Base, j.Raw, nil)
+// Poll for current data. Optional parameters - a *Tree selecting the fields
+// to fetch, or the depth as int.
+func (j *Job) Poll(options ...interface{}) (int, error) {
+	return j.PollContext(context.Background(), options...)
+}
+
+func (j *Job) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, j.Raw, pollQuery(options))
 	if err != nil {
 		return 0, err
 	}
@@ -503,9 +613,14 @@ func (j *Job) Poll() (int, error) {
 }
 
 func (j *Job) History() ([]*History, error) {
-	resp, err := j.Jenkins.Requester.Get(j.Base+"/buildHistory/ajax", nil, nil)
+	return j.HistoryContext(context.Background())
+}
+
+func (j *Job) HistoryContext(ctx context.Context) ([]*History, error) {
+	resp, err := j.Jenkins.Requester.GetStreamContext(ctx, j.Base+"/buildHistory/ajax", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	return parseBuildHistory(resp.Body), nil
 }
@@ -14,6 +14,8 @@
 
 package gojenkins
 
+import "context"
+
 type Label struct {
 	Raw     *LabelResponse
 	Jenkins *Jenkins
@@ -53,8 +55,14 @@ func (l *Label) GetNodes() []LabelNode {
 	return l.Raw.Nodes
 }
 
-func (l *Label) Poll() (int, error) {
-	response, err := l.Jenkins.Requester.GetJSON(l.Base, l.Raw, nil)
+// Poll for current data. Optional parameters - a *Tree selecting the fields
+// to fetch, or the depth as int.
+func (l *Label) Poll(options ...interface{}) (int, error) {
+	return l.PollContext(context.Background(), options...)
+}
+
+func (l *Label) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := l.Jenkins.Requester.GetJSONContext(ctx, l.Base, l.Raw, pollQuery(options))
 	if err != nil {
 		return 0, err
 	}
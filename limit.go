@@ -0,0 +1,187 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Priority of a request waiting for the Limiter. Requests of a higher priority
+// are always let through first, so a crawl running at PriorityBulk does not
+// hold up interactive calls.
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota
+	PriorityNormal
+	PriorityBulk
+
+	numPriorities = 3
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests wait for the Limiter at the
+// given priority. Requests without a priority are PriorityNormal.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < numPriorities {
+		return p
+	}
+	return PriorityNormal
+}
+
+// Limiter bounds the rate and the concurrency of requests. Every attempt
+// takes a token from a bucket that refills at a fixed rate per second, and
+// occupies one of the in-flight slots until its response body is closed. A
+// zero rate or in-flight maximum disables that limit.
+//
+// A Limiter may be shared by several Requesters talking to the same Jenkins.
+type Limiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	maxInFlight int
+	inFlight    int
+	waiters     [numPriorities][]chan struct{}
+	timer       *time.Timer
+}
+
+// NewLimiter creates a limiter allowing rate requests per second with bursts
+// of up to burst requests, and at most maxInFlight concurrent requests.
+func NewLimiter(rate float64, burst int, maxInFlight int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:        rate,
+		burst:       float64(burst),
+		tokens:      float64(burst),
+		last:        time.Now(),
+		maxInFlight: maxInFlight,
+	}
+}
+
+// Wait blocks until a request of priority p may be sent, or ctx is done. The
+// returned function must be called once the request is finished.
+func (l *Limiter) Wait(ctx context.Context, p Priority) (release func(), err error) {
+	if p < 0 || p >= numPriorities {
+		p = PriorityNormal
+	}
+	ready := make(chan struct{})
+	l.mu.Lock()
+	l.waiters[p] = append(l.waiters[p], ready)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return l.releaseFunc(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready:
+			// Granted while we were giving up, hand the slot back.
+			l.inFlight--
+			l.dispatch()
+		default:
+			l.remove(p, ready)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Limiter) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inFlight--
+			l.dispatch()
+			l.mu.Unlock()
+		})
+	}
+}
+
+func (l *Limiter) remove(p Priority, ready chan struct{}) {
+	for i, w := range l.waiters[p] {
+		if w == ready {
+			l.waiters[p] = append(l.waiters[p][:i], l.waiters[p][i+1:]...)
+			return
+		}
+	}
+}
+
+// Let waiting requests through, highest priority first, as long as there are
+// tokens and free slots. Called with l.mu held.
+func (l *Limiter) dispatch() {
+	for {
+		p := Priority(0)
+		for p < numPriorities && len(l.waiters[p]) == 0 {
+			p++
+		}
+		if p == numPriorities {
+			return
+		}
+		if l.maxInFlight > 0 && l.inFlight >= l.maxInFlight {
+			return
+		}
+		if l.rate > 0 {
+			now := time.Now()
+			l.tokens += now.Sub(l.last).Seconds() * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+			l.last = now
+			if l.tokens < 1 {
+				if l.timer == nil {
+					wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+					l.timer = time.AfterFunc(wait, func() {
+						l.mu.Lock()
+						l.timer = nil
+						l.dispatch()
+						l.mu.Unlock()
+					})
+				}
+				return
+			}
+			l.tokens--
+		}
+		l.inFlight++
+		close(l.waiters[p][0])
+		l.waiters[p] = l.waiters[p][1:]
+	}
+}
+
+// Frees the limiter slot once the body has been closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
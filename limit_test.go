@@ -0,0 +1,73 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	inFlight, maxInFlight := 0, 0
+	var mu sync.Mutex
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(`{}`))
+	})
+	defer done()
+	r.Limiter = NewLimiter(0, 1, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.GetJSON("/job/a", new(JobResponse), nil)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, maxInFlight)
+
+	// Waiting interactive requests go before bulk ones.
+	l := NewLimiter(0, 1, 1)
+	release, err := l.Wait(context.Background(), PriorityNormal)
+	assert.Nil(t, err)
+	var order []Priority
+	for _, p := range []Priority{PriorityBulk, PriorityInteractive} {
+		wg.Add(1)
+		go func(p Priority) {
+			defer wg.Done()
+			release, _ := l.Wait(context.Background(), p)
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+			release()
+		}(p)
+		time.Sleep(10 * time.Millisecond)
+	}
+	release()
+	wg.Wait()
+	assert.Equal(t, []Priority{PriorityInteractive, PriorityBulk}, order)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	l = NewLimiter(1, 1, 0)
+	_, err = l.Wait(ctx, PriorityNormal)
+	assert.Nil(t, err)
+	_, err = l.Wait(ctx, PriorityNormal)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,191 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Logger receives the log messages of a client. Arguments after the message
+// are alternating keys and values. *slog.Logger satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// The Logger in use. Without one, messages go to the package-level loggers.
+func (r *Requester) logger() Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return globalLogger{}
+}
+
+// NewStdLogger adapts a *log.Logger to the Logger interface. Messages below
+// level are dropped, e.g. NewStdLogger(l, slog.LevelWarn) skips Debug and Info.
+func NewStdLogger(l *log.Logger, level slog.Level) Logger {
+	return &stdLogger{l: l, level: level}
+}
+
+type stdLogger struct {
+	l     *log.Logger
+	level slog.Level
+}
+
+func (s *stdLogger) Debug(msg string, args ...interface{}) {
+	s.log(slog.LevelDebug, msg, args)
+}
+
+func (s *stdLogger) Info(msg string, args ...interface{}) {
+	s.log(slog.LevelInfo, msg, args)
+}
+
+func (s *stdLogger) Warn(msg string, args ...interface{}) {
+	s.log(slog.LevelWarn, msg, args)
+}
+
+func (s *stdLogger) Error(msg string, args ...interface{}) {
+	s.log(slog.LevelError, msg, args)
+}
+
+func (s *stdLogger) log(level slog.Level, msg string, args []interface{}) {
+	if level < s.level {
+		return
+	}
+	s.l.Output(3, level.String()+": "+formatMessage(msg, args))
+}
+
+// DiscardLogger drops all messages.
+var DiscardLogger Logger = discardLogger{}
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(msg string, args ...interface{}) {}
+func (discardLogger) Info(msg string, args ...interface{})  {}
+func (discardLogger) Warn(msg string, args ...interface{})  {}
+func (discardLogger) Error(msg string, args ...interface{}) {}
+
+// Logger writing to the package-level Debug, Info, Warning and Error loggers.
+type globalLogger struct{}
+
+func (globalLogger) Debug(msg string, args ...interface{}) {
+	output(Debug, msg, args)
+}
+
+func (globalLogger) Info(msg string, args ...interface{}) {
+	output(Info, msg, args)
+}
+
+func (globalLogger) Warn(msg string, args ...interface{}) {
+	output(Warning, msg, args)
+}
+
+func (globalLogger) Error(msg string, args ...interface{}) {
+	output(Error, msg, args)
+}
+
+func output(l *log.Logger, msg string, args []interface{}) {
+	if l == nil {
+		return
+	}
+	l.Output(4, formatMessage(msg, args))
+}
+
+// Render a message with key/value pairs as `msg key=value ...`.
+func formatMessage(msg string, args []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+	return b.String()
+}
+
+const redacted = "REDACTED"
+
+// Headers that carry credentials, on top of the ones set by the Authenticator
+// and the crumb header.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Copy of h with credentials and crumbs replaced. Request headers set by the
+// Authenticator are replaced as well.
+func (r *Requester) redactHeaders(h http.Header, request bool) http.Header {
+	keys := append([]string(nil), sensitiveHeaders...)
+	if auth := r.authenticator(); auth != nil && request {
+		keys = append(keys, authHeaderKeys(auth, h)...)
+	}
+
+	out := h.Clone()
+	for _, k := range keys {
+		if _, ok := out[http.CanonicalHeaderKey(k)]; ok {
+			out.Set(k, redacted)
+		}
+	}
+	// Crumb issuers name their header after the crumb, e.g. Jenkins-Crumb.
+	for k := range out {
+		if strings.Contains(strings.ToLower(k), "crumb") {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// URL with the remote build trigger token removed from the query.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	if q.Get("token") == "" {
+		return u.String()
+	}
+	q.Set("token", redacted)
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func (r *Requester) logRequest(req *http.Request) {
+	r.logger().Debug("Jenkins request",
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"headers", r.redactHeaders(req.Header, true))
+}
+
+func (r *Requester) logResponse(req *http.Request, response *http.Response, err error, elapsed time.Duration) {
+	if err != nil {
+		r.logger().Debug("Jenkins request failed",
+			"method", req.Method,
+			"url", redactURL(req.URL),
+			"elapsed", elapsed,
+			"error", err)
+		return
+	}
+	r.logger().Debug("Jenkins response",
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"status", response.StatusCode,
+		"elapsed", elapsed,
+		"headers", r.redactHeaders(response.Header, false))
+}
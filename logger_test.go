@@ -0,0 +1,50 @@
+package gojenkins
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugLogging(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID.abc", Value: "s3ss10n"})
+		w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"cr4mb"}`))
+	})
+	defer done()
+	var buf bytes.Buffer
+	r.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r.DebugHTTP = true
+	r.BasicAuth = &BasicAuth{Username: "admin", Password: "hunter2"}
+
+	_, err := r.Post("/job/a/build", nil, nil, map[string]string{"token": "s3cr3t"})
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "Jenkins response")
+	assert.Contains(t, buf.String(), "REDACTED")
+	for _, secret := range []string{"YWRtaW46aHVudGVyMg", "cr4mb", "s3ss10n", "s3cr3t"} {
+		assert.NotContains(t, buf.String(), secret)
+	}
+}
+
+func TestDebugLoggingWithoutLogger(t *testing.T) {
+	jenkins, err := NewClient("http://127.0.0.1:1", WithoutConnectivityCheck(), WithDebugLogging())
+	assert.Nil(t, err)
+	if assert.IsType(t, &stdLogger{}, jenkins.Requester.Logger) {
+		assert.Equal(t, slog.LevelDebug, jenkins.Requester.Logger.(*stdLogger).level)
+	}
+
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {})
+	defer done()
+	r.DebugHTTP = true
+	var buf bytes.Buffer
+	defer func(l *log.Logger) { Debug = l }(Debug)
+	Debug = log.New(&buf, "DEBUG: ", 0)
+
+	_, err = r.Get("/job/a", nil, nil)
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "DEBUG: Jenkins response")
+}
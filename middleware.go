@@ -0,0 +1,157 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Middleware wraps the transport used for every request the Requester sends,
+// including retries, crumb fetches and redirects. Middleware added first is
+// the outermost one and sees the request first.
+//
+// Use RequestEndpoint to label metrics and spans with the endpoint template
+// instead of the raw URL.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// HookMiddleware builds a Middleware from a pair of hooks. Either may be nil.
+// before is called right before the request is sent, after once the response
+// headers arrived or the request failed.
+func HookMiddleware(before func(req *http.Request), after func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if before != nil {
+				before(req)
+			}
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if after != nil {
+				after(req, resp, err, time.Since(start))
+			}
+			return resp, err
+		})
+	}
+}
+
+// Use appends middleware to the chain of the Requester.
+func (r *Requester) Use(mw ...Middleware) {
+	r.Middleware = append(r.Middleware, mw...)
+}
+
+// The client used to send a request, with the middleware chain wrapped around
+// its transport. The copy shares the cookie jar and redirect policy.
+func (r *Requester) httpClient() *http.Client {
+	if len(r.Middleware) == 0 {
+		return r.Client
+	}
+	client := *r.Client
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(r.Middleware) - 1; i >= 0; i-- {
+		transport = r.Middleware[i](transport)
+	}
+	client.Transport = transport
+	return &client
+}
+
+type endpointKey struct{}
+
+func withEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, EndpointTemplate(endpoint))
+}
+
+// RequestEndpoint returns the endpoint template of a request sent by a
+// Requester, e.g. "/job/{name}/{number}/api/json". For requests that did not
+// come from a Requester, the template is derived from the URL path.
+func RequestEndpoint(req *http.Request) string {
+	if endpoint, ok := req.Context().Value(endpointKey{}).(string); ok {
+		return endpoint
+	}
+	return EndpointTemplate(req.URL.Path)
+}
+
+// Path segments that are followed by the name of an item.
+var namedSegments = map[string]string{
+	"job":         "{name}",
+	"view":        "{view}",
+	"computer":    "{node}",
+	"label":       "{label}",
+	"user":        "{user}",
+	"fingerprint": "{id}",
+	"plugin":      "{plugin}",
+}
+
+// Segments after a named segment that are actions rather than names.
+var actionSegments = map[string]bool{
+	"":             true,
+	"api":          true,
+	"createItem":   true,
+	"createView":   true,
+	"doCreateItem": true,
+}
+
+// EndpointTemplate replaces the job, view and node names, build numbers and
+// artifact paths in an endpoint with placeholders, so that it can be used as
+// a low-cardinality metric label:
+//
+//	/job/folder/job/app/42/api/json -> /job/{name}/job/{name}/{number}/api/json
+func EndpointTemplate(endpoint string) string {
+	if i := strings.IndexAny(endpoint, "?#"); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	segments := strings.Split(endpoint, "/")
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		if placeholder, ok := namedSegments[segment]; ok && i+1 < len(segments) && !actionSegments[segments[i+1]] {
+			segments[i+1] = placeholder
+			i++
+			continue
+		}
+		if segment == "artifact" || segment == "ws" {
+			if i+1 < len(segments) && segments[i+1] != "" {
+				segments = append(segments[:i+1], "{path}")
+			}
+			break
+		}
+		if isNumber(segment) {
+			segments[i] = "{number}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package gojenkins
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	defer done()
+	var calls []string
+	trace := func(name string) Middleware {
+		return HookMiddleware(func(req *http.Request) {
+			calls = append(calls, name+" "+RequestEndpoint(req))
+		}, func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+			calls = append(calls, name+" "+strconv.Itoa(resp.StatusCode))
+		})
+	}
+	r.Use(trace("outer"), trace("inner"))
+
+	_, err := r.GetJSON("/job/folder/job/app/42", new(BuildResponse), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"outer /job/{name}/job/{name}/{number}/api/json",
+		"inner /job/{name}/job/{name}/{number}/api/json",
+		"inner 200",
+		"outer 200",
+	}, calls)
+
+	assert.Equal(t, "/job/{name}/{number}/artifact/{path}", EndpointTemplate("/job/app/7/artifact/target/app.jar"))
+	assert.Equal(t, "/computer/api/json", EndpointTemplate("/computer/api/json"))
+	assert.Equal(t, "/view/{view}/createItem", EndpointTemplate("/view/All/createItem?name=x"))
+}
@@ -0,0 +1,310 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a client created by NewClient.
+type Option func(*clientConfig) error
+
+type clientConfig struct {
+	httpClient            *http.Client
+	transport             http.RoundTripper
+	tlsConfig             *tls.Config
+	proxy                 func(*http.Request) (*url.URL, error)
+	timeout               time.Duration
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	auth                  Authenticator
+	retry                 *RetryPolicy
+	userAgent             string
+	logger                Logger
+	debugHTTP             bool
+	middleware            []Middleware
+	limiter               *Limiter
+	rateLimit             float64
+	rateBurst             int
+	maxInFlight           int
+	cache                 *Cache
+	skipConnectivityCheck bool
+}
+
+// Transport used unless the caller brings their own: the settings of
+// http.DefaultTransport, which reads the proxy from the environment and has
+// timeouts for dialing and the TLS handshake.
+func newTransport() *http.Transport {
+	return http.DefaultTransport.(*http.Transport).Clone()
+}
+
+func (c *clientConfig) newTransport() *http.Transport {
+	tr := newTransport()
+	tr.Proxy = c.proxy
+	if c.tlsConfig != nil {
+		tr.TLSClientConfig = c.tlsConfig
+	}
+	if c.dialTimeout > 0 {
+		tr.DialContext = (&net.Dialer{Timeout: c.dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if c.tlsHandshakeTimeout > 0 {
+		tr.TLSHandshakeTimeout = c.tlsHandshakeTimeout
+	}
+	if c.responseHeaderTimeout > 0 {
+		tr.ResponseHeaderTimeout = c.responseHeaderTimeout
+	}
+	return tr
+}
+
+func (c *clientConfig) ensureTLS() *tls.Config {
+	if c.tlsConfig == nil {
+		c.tlsConfig = &tls.Config{}
+	}
+	return c.tlsConfig
+}
+
+// Use this HTTP client as is. All transport related options are ignored.
+// The client should have a cookie jar, Jenkins binds crumbs to the session.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *clientConfig) error {
+		c.httpClient = client
+		return nil
+	}
+}
+
+// Use this transport as is. TLS, proxy and transport timeout options are ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *clientConfig) error {
+		c.transport = transport
+		return nil
+	}
+}
+
+// Use a copy of this TLS configuration. Options adding certificates apply on top of it.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *clientConfig) error {
+		c.tlsConfig = config.Clone()
+		return nil
+	}
+}
+
+// Trust the CA certificates in this PEM data, instead of the system roots.
+func WithCACert(pem []byte) Option {
+	return func(c *clientConfig) error {
+		cfg := c.ensureTLS()
+		if cfg.RootCAs == nil {
+			cfg.RootCAs = x509.NewCertPool()
+		}
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return errors.New("jenkins: no CA certificate found in PEM data")
+		}
+		return nil
+	}
+}
+
+// Present this client certificate, for Jenkins behind mutual TLS.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(c *clientConfig) error {
+		cfg := c.ensureTLS()
+		cfg.Certificates = append(cfg.Certificates, cert)
+		return nil
+	}
+}
+
+// Present the client certificate from these PEM files, for Jenkins behind mutual TLS.
+func WithClientCertificateFile(certFile string, keyFile string) Option {
+	return func(c *clientConfig) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		return WithClientCertificate(cert)(c)
+	}
+}
+
+// Skip verification of the server certificate.
+func WithInsecureSkipVerify() Option {
+	return func(c *clientConfig) error {
+		c.ensureTLS().InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// Choose the proxy for each request, e.g. http.ProxyURL(u). A nil proxy disables proxies.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *clientConfig) error {
+		c.proxy = proxy
+		return nil
+	}
+}
+
+// Read the proxy from HTTP_PROXY, HTTPS_PROXY and NO_PROXY. This is the default.
+func WithProxyFromEnvironment() Option {
+	return WithProxy(http.ProxyFromEnvironment)
+}
+
+// Limit the total time of a request, including reading the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) error {
+		c.timeout = timeout
+		return nil
+	}
+}
+
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) error {
+		c.dialTimeout = timeout
+		return nil
+	}
+}
+
+func WithTLSHandshakeTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) error {
+		c.tlsHandshakeTimeout = timeout
+		return nil
+	}
+}
+
+// Limit the time to wait for the response headers after the request was sent.
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) error {
+		c.responseHeaderTimeout = timeout
+		return nil
+	}
+}
+
+func WithAuth(auth Authenticator) Option {
+	return func(c *clientConfig) error {
+		c.auth = auth
+		return nil
+	}
+}
+
+func WithBasicAuth(username string, password string) Option {
+	return WithAuth(&BasicAuth{Username: username, Password: password})
+}
+
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *clientConfig) error {
+		c.retry = policy
+		return nil
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *clientConfig) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+func WithLogger(logger Logger) Option {
+	return func(c *clientConfig) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// Log every request and response at debug level. Credentials, cookies and
+// crumbs are redacted. Without WithLogger, the client logs to standard error,
+// debug messages included.
+func WithDebugLogging() Option {
+	return func(c *clientConfig) error {
+		c.debugHTTP = true
+		return nil
+	}
+}
+
+// Wrap every request in the given middleware, e.g. for metrics or tracing.
+// See Requester.Use.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *clientConfig) error {
+		c.middleware = append(c.middleware, mw...)
+		return nil
+	}
+}
+
+// Bound the rate and concurrency of requests with l, which may be shared by
+// several clients. Use NewLimiter, or WithRateLimit and WithMaxInFlight for a
+// limiter of this client only. The options cannot be combined.
+func WithLimiter(l *Limiter) Option {
+	return func(c *clientConfig) error {
+		c.limiter = l
+		return nil
+	}
+}
+
+// Send at most perSecond requests per second, with bursts of up to burst
+// requests.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *clientConfig) error {
+		if perSecond <= 0 {
+			return fmt.Errorf("jenkins: invalid rate limit %v", perSecond)
+		}
+		c.rateLimit = perSecond
+		c.rateBurst = burst
+		return nil
+	}
+}
+
+// Have at most n requests waiting for Jenkins at the same time.
+func WithMaxInFlight(n int) Option {
+	return func(c *clientConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("jenkins: invalid number of requests in flight %d", n)
+		}
+		c.maxInFlight = n
+		return nil
+	}
+}
+
+// The limiter passed to WithLimiter, or a new one for WithRateLimit and
+// WithMaxInFlight. A shared limiter is never changed.
+func (c *clientConfig) newLimiter() (*Limiter, error) {
+	if c.rateLimit == 0 && c.maxInFlight == 0 {
+		return c.limiter, nil
+	}
+	if c.limiter != nil {
+		return nil, errors.New("jenkins: WithLimiter cannot be combined with WithRateLimit or WithMaxInFlight")
+	}
+	return NewLimiter(c.rateLimit, c.rateBurst, c.maxInFlight), nil
+}
+
+// Cache api/json responses, e.g.
+//
+//	gojenkins.WithCache(gojenkins.NewCache(gojenkins.NewMemoryStore(1000), 5*time.Second))
+func WithCache(c *Cache) Option {
+	return func(cfg *clientConfig) error {
+		cfg.cache = c
+		return nil
+	}
+}
+
+// Do not contact Jenkins in NewClient. Jenkins.Version and Jenkins.Raw stay
+// empty until Poll is called.
+func WithoutConnectivityCheck() Option {
+	return func(c *clientConfig) error {
+		c.skipConnectivityCheck = true
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "deploy-bot/1.0", req.UserAgent())
+		assert.Equal(t, "Bearer t0k3n", req.Header.Get("Authorization"))
+		w.Header().Set("X-Jenkins", "2.401")
+		w.Write([]byte(`{"nodeName":"master"}`))
+	}))
+	defer server.Close()
+
+	jenkins, err := NewClient(server.URL+"/", WithUserAgent("deploy-bot/1.0"), WithAuth(&BearerTokenAuth{Token: "t0k3n"}), WithTimeout(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL, jenkins.Server)
+	assert.Equal(t, "2.401", jenkins.Version)
+	assert.Equal(t, "master", jenkins.Raw.NodeName)
+
+	_, err = NewClient("http://127.0.0.1:1", WithoutConnectivityCheck())
+	assert.Nil(t, err)
+	_, err = NewClient("http://127.0.0.1:1", WithCACert([]byte("garbage")))
+	assert.NotNil(t, err)
+}
+
+func TestLimiterOptions(t *testing.T) {
+	shared := NewLimiter(10, 2, 3)
+	_, err := NewClient("http://127.0.0.1:1", WithoutConnectivityCheck(), WithLimiter(shared), WithRateLimit(1, 1))
+	assert.NotNil(t, err)
+	_, err = NewClient("http://127.0.0.1:1", WithoutConnectivityCheck(), WithMaxInFlight(1), WithLimiter(shared))
+	assert.NotNil(t, err)
+	assert.Equal(t, 10.0, shared.rate)
+	assert.Equal(t, 3, shared.maxInFlight)
+
+	jenkins, err := NewClient("http://127.0.0.1:1", WithoutConnectivityCheck(), WithLimiter(shared))
+	assert.Nil(t, err)
+	assert.Equal(t, shared, jenkins.Requester.Limiter)
+
+	jenkins, err = NewClient("http://127.0.0.1:1", WithoutConnectivityCheck(), WithRateLimit(5, 2), WithMaxInFlight(4))
+	assert.Nil(t, err)
+	assert.Equal(t, 5.0, jenkins.Requester.Limiter.rate)
+	assert.Equal(t, 2.0, jenkins.Requester.Limiter.burst)
+	assert.Equal(t, 4, jenkins.Requester.Limiter.maxInFlight)
+}
@@ -0,0 +1,101 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package otelhook traces the requests a gojenkins client sends with
+// OpenTelemetry:
+//
+//	jenkins, err := gojenkins.NewClient(url, gojenkins.WithMiddleware(otelhook.Middleware()))
+//
+// Every request becomes a client span named after the method and endpoint
+// template, e.g. "GET /job/{name}/{number}/api/json", and the trace context
+// is propagated to Jenkins in the request headers.
+package otelhook
+
+import (
+	"net/http"
+
+	"github.com/appscode/gojenkins"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/appscode/gojenkins/otelhook"
+
+type config struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// Option configures the middleware.
+type Option func(*config)
+
+// Use provider instead of the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// Use propagator instead of the global text map propagator.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// Middleware starts a span for every request passing through it. The span
+// ends when the response headers arrive, and is marked as failed for
+// transport errors and 4xx/5xx responses.
+func Middleware(opts ...Option) gojenkins.Middleware {
+	cfg := &config{
+		provider:   otel.GetTracerProvider(),
+		propagator: otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	tracer := cfg.provider.Tracer(instrumentationName)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return gojenkins.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint := gojenkins.RequestEndpoint(req)
+			ctx, span := tracer.Start(req.Context(), req.Method+" "+endpoint,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("server.address", req.URL.Hostname()),
+					attribute.String("jenkins.endpoint", endpoint),
+				))
+			defer span.End()
+
+			req = req.Clone(ctx)
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= 400 {
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// PipelineRun is a Pipeline build as described by the workflow REST API
+// (/wfapi) of the Pipeline Stage View plugin.
+type PipelineRun struct {
+	Build *Build
+	Raw   *PipelineRunResponse
+}
+
+type PipelineRunResponse struct {
+	ID                  string                 `json:"id"`
+	Name                string                 `json:"name"`
+	Status              string                 `json:"status"`
+	StartTimeMillis     int64                  `json:"startTimeMillis"`
+	EndTimeMillis       int64                  `json:"endTimeMillis"`
+	DurationMillis      int64                  `json:"durationMillis"`
+	QueueDurationMillis int64                  `json:"queueDurationMillis"`
+	PauseDurationMillis int64                  `json:"pauseDurationMillis"`
+	Stages              []PipelineNodeResponse `json:"stages"`
+}
+
+// PipelineNodeResponse is a stage or a step of a Pipeline run.
+type PipelineNodeResponse struct {
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	ExecNode             string   `json:"execNode"`
+	Status               string   `json:"status"`
+	ParameterDescription string   `json:"parameterDescription"`
+	StartTimeMillis      int64    `json:"startTimeMillis"`
+	DurationMillis       int64    `json:"durationMillis"`
+	PauseDurationMillis  int64    `json:"pauseDurationMillis"`
+	ParentNodes          []string `json:"parentNodes"`
+	Error                *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+	StageFlowNodes []PipelineNodeResponse `json:"stageFlowNodes"`
+}
+
+// PipelineStage is a stage of a Pipeline run.
+type PipelineStage struct {
+	Run *PipelineRun
+	Raw *PipelineNodeResponse
+	// Stages run inside this one, e.g. its parallel branches.
+	Branches []*PipelineStage
+}
+
+// PipelineStep is a step run in a stage.
+type PipelineStep struct {
+	Run *PipelineRun
+	Raw *PipelineNodeResponse
+}
+
+// PipelineNodeLog is the log of a single step. Long logs are cut short, see
+// HasMore, the whole log can be read at ConsoleURL.
+type PipelineNodeLog struct {
+	NodeID     string `json:"nodeId"`
+	NodeStatus string `json:"nodeStatus"`
+	Length     int64  `json:"length"`
+	HasMore    bool   `json:"hasMore"`
+	Text       string `json:"text"`
+	ConsoleURL string `json:"consoleUrl"`
+}
+
+// GetPipelineRun describes a Pipeline build, its stages and their status.
+// Fails with ErrNotFound for builds of other job types, or if the Pipeline
+// Stage View plugin is not installed.
+func (b *Build) GetPipelineRun() (*PipelineRun, error) {
+	return b.GetPipelineRunContext(context.Background())
+}
+
+func (b *Build) GetPipelineRunContext(ctx context.Context) (*PipelineRun, error) {
+	run := &PipelineRun{Build: b, Raw: new(PipelineRunResponse)}
+	if _, err := run.PollContext(ctx); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (p *PipelineRun) Poll() (int, error) {
+	return p.PollContext(context.Background())
+}
+
+func (p *PipelineRun) PollContext(ctx context.Context) (int, error) {
+	return p.getWfapi(ctx, p.Build.Base+"/wfapi/describe", p.Raw)
+}
+
+// The workflow API lives next to api/json, not below it.
+func (p *PipelineRun) getWfapi(ctx context.Context, endpoint string, responseStruct interface{}) (int, error) {
+	ar := NewAPIRequest("GET", endpoint, nil)
+	ar.Suffix = ""
+	response, err := p.Build.Jenkins.Requester.DoContext(ctx, ar, responseStruct)
+	if err != nil {
+		return 0, err
+	}
+	return response.StatusCode, nil
+}
+
+func (p *PipelineRun) nodeEndpoint(id string) string {
+	return p.Build.Base + "/execution/node/" + id + "/wfapi"
+}
+
+// GetStatus returns e.g. "SUCCESS", "FAILED", "IN_PROGRESS" or
+// "PAUSED_PENDING_INPUT".
+func (p *PipelineRun) GetStatus() string {
+	return p.Raw.Status
+}
+
+func (p *PipelineRun) IsRunning() bool {
+	return p.Raw.Status == PIPELINE_STATUS_IN_PROGRESS || p.Raw.Status == PIPELINE_STATUS_PAUSED
+}
+
+func (p *PipelineRun) GetStartTime() time.Time {
+	return millisToTime(p.Raw.StartTimeMillis)
+}
+
+func (p *PipelineRun) GetDuration() time.Duration {
+	return time.Duration(p.Raw.DurationMillis) * time.Millisecond
+}
+
+func (p *PipelineRun) GetQueueDuration() time.Duration {
+	return time.Duration(p.Raw.QueueDurationMillis) * time.Millisecond
+}
+
+// GetPauseDuration returns how long the run waited, e.g. for input.
+func (p *PipelineRun) GetPauseDuration() time.Duration {
+	return time.Duration(p.Raw.PauseDurationMillis) * time.Millisecond
+}
+
+// GetStages returns the top level stages in the order they started. The
+// workflow API lists nested stages and parallel branches next to the other
+// stages, so each stage is described to read its parent nodes: a stage whose
+// parent is the node of an earlier stage, or one of its steps, is returned in
+// the Branches of that stage.
+func (p *PipelineRun) GetStages() ([]*PipelineStage, error) {
+	return p.GetStagesContext(context.Background())
+}
+
+func (p *PipelineRun) GetStagesContext(ctx context.Context) ([]*PipelineStage, error) {
+	var stages []*PipelineStage
+	// The innermost stage each node ran in, by node id.
+	owners := map[string]*PipelineStage{}
+	for i := range p.Raw.Stages {
+		stage := &PipelineStage{Run: p, Raw: &p.Raw.Stages[i]}
+		if _, err := p.getWfapi(ctx, p.nodeEndpoint(stage.Raw.ID)+"/describe", stage.Raw); err != nil {
+			return nil, err
+		}
+		if parent := stage.parent(owners); parent != nil {
+			parent.Branches = append(parent.Branches, stage)
+		} else {
+			stages = append(stages, stage)
+		}
+		owners[stage.Raw.ID] = stage
+		for _, node := range stage.Raw.StageFlowNodes {
+			owners[node.ID] = stage
+		}
+	}
+	return stages, nil
+}
+
+func (p *PipelineStage) parent(owners map[string]*PipelineStage) *PipelineStage {
+	for _, id := range p.Raw.ParentNodes {
+		if owner, ok := owners[id]; ok {
+			return owner
+		}
+	}
+	return nil
+}
+
+func (p *PipelineStage) GetID() string {
+	return p.Raw.ID
+}
+
+func (p *PipelineStage) GetName() string {
+	return p.Raw.Name
+}
+
+// GetStatus returns e.g. "SUCCESS", "FAILED", "IN_PROGRESS" or
+// "NOT_EXECUTED" for stages that were skipped.
+func (p *PipelineStage) GetStatus() string {
+	return p.Raw.Status
+}
+
+// IsParallel reports whether some of the Branches started from the same
+// node, i.e. ran in parallel.
+func (p *PipelineStage) IsParallel() bool {
+	started := map[string]bool{}
+	for _, branch := range p.Branches {
+		for _, id := range branch.Raw.ParentNodes {
+			if started[id] {
+				return true
+			}
+			started[id] = true
+		}
+	}
+	return false
+}
+
+func (p *PipelineStage) GetStartTime() time.Time {
+	return millisToTime(p.Raw.StartTimeMillis)
+}
+
+func (p *PipelineStage) GetDuration() time.Duration {
+	return time.Duration(p.Raw.DurationMillis) * time.Millisecond
+}
+
+func (p *PipelineStage) GetPauseDuration() time.Duration {
+	return time.Duration(p.Raw.PauseDurationMillis) * time.Millisecond
+}
+
+// GetError returns the message of the error that failed the stage, if any.
+func (p *PipelineStage) GetError() string {
+	if p.Raw.Error == nil {
+		return ""
+	}
+	return p.Raw.Error.Message
+}
+
+// GetSteps fetches the steps run in the stage.
+func (p *PipelineStage) GetSteps() ([]*PipelineStep, error) {
+	return p.GetStepsContext(context.Background())
+}
+
+func (p *PipelineStage) GetStepsContext(ctx context.Context) ([]*PipelineStep, error) {
+	if _, err := p.Run.getWfapi(ctx, p.Run.nodeEndpoint(p.Raw.ID)+"/describe", p.Raw); err != nil {
+		return nil, err
+	}
+	steps := make([]*PipelineStep, len(p.Raw.StageFlowNodes))
+	for i := range p.Raw.StageFlowNodes {
+		steps[i] = &PipelineStep{Run: p.Run, Raw: &p.Raw.StageFlowNodes[i]}
+	}
+	return steps, nil
+}
+
+// GetLog returns the logs of all steps of the stage, one after the other.
+// Logs of long steps are cut short as in PipelineStep.GetLog.
+func (p *PipelineStage) GetLog() (string, error) {
+	return p.GetLogContext(context.Background())
+}
+
+func (p *PipelineStage) GetLogContext(ctx context.Context) (string, error) {
+	steps, err := p.GetStepsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for _, step := range steps {
+		log, err := step.GetLogContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		text.WriteString(log.Text)
+	}
+	return text.String(), nil
+}
+
+func (p *PipelineStep) GetID() string {
+	return p.Raw.ID
+}
+
+func (p *PipelineStep) GetName() string {
+	return p.Raw.Name
+}
+
+// GetDescription returns the main argument of the step, e.g. the script of
+// an sh step.
+func (p *PipelineStep) GetDescription() string {
+	return p.Raw.ParameterDescription
+}
+
+func (p *PipelineStep) GetStatus() string {
+	return p.Raw.Status
+}
+
+func (p *PipelineStep) GetDuration() time.Duration {
+	return time.Duration(p.Raw.DurationMillis) * time.Millisecond
+}
+
+func (p *PipelineStep) GetPauseDuration() time.Duration {
+	return time.Duration(p.Raw.PauseDurationMillis) * time.Millisecond
+}
+
+func (p *PipelineStep) GetLog() (*PipelineNodeLog, error) {
+	return p.GetLogContext(context.Background())
+}
+
+func (p *PipelineStep) GetLogContext(ctx context.Context) (*PipelineNodeLog, error) {
+	log := new(PipelineNodeLog)
+	if _, err := p.Run.getWfapi(ctx, p.Run.nodeEndpoint(p.Raw.ID)+"/log", log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func millisToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,71 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineRun(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/a/7/wfapi/describe/":
+			w.Write([]byte(`{"id":"7","status":"IN_PROGRESS","durationMillis":9000,"pauseDurationMillis":2000,"stages":[
+				{"id":"6","name":"Build","status":"SUCCESS","startTimeMillis":1000,"durationMillis":3000},
+				{"id":"12","name":"Tests","status":"IN_PROGRESS","startTimeMillis":4000,"durationMillis":6000},
+				{"id":"15","name":"Integration","status":"IN_PROGRESS","startTimeMillis":4010,"durationMillis":5990},
+				{"id":"16","name":"Unit","status":"FAILED","startTimeMillis":4010,"durationMillis":1000,"error":{"message":"tests failed"}}]}`))
+		case "/job/a/7/execution/node/6/wfapi/describe/":
+			w.Write([]byte(`{"id":"6","name":"Build","parentNodes":["3"],"stageFlowNodes":[
+				{"id":"7","name":"Shell Script","parameterDescription":"make","status":"SUCCESS","parentNodes":["6"]},
+				{"id":"8","name":"Shell Script","parameterDescription":"make test","status":"SUCCESS","parentNodes":["7"]}]}`))
+		case "/job/a/7/execution/node/12/wfapi/describe/":
+			w.Write([]byte(`{"id":"12","name":"Tests","parentNodes":["10"],"stageFlowNodes":[
+				{"id":"14","name":"Execute in parallel","status":"IN_PROGRESS","parentNodes":["13"]}]}`))
+		case "/job/a/7/execution/node/15/wfapi/describe/":
+			w.Write([]byte(`{"id":"15","name":"Integration","parentNodes":["14"],"stageFlowNodes":[
+				{"id":"18","name":"Shell Script","status":"IN_PROGRESS","parentNodes":["15"]}]}`))
+		case "/job/a/7/execution/node/16/wfapi/describe/":
+			w.Write([]byte(`{"id":"16","name":"Unit","parentNodes":["14"],"stageFlowNodes":[
+				{"id":"17","name":"Shell Script","status":"FAILED","parentNodes":["16"]}]}`))
+		case "/job/a/7/execution/node/7/wfapi/log/":
+			w.Write([]byte(`{"nodeId":"7","text":"compiling\n"}`))
+		case "/job/a/7/execution/node/8/wfapi/log/":
+			w.Write([]byte(`{"nodeId":"8","text":"testing\n","hasMore":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer done()
+	build := &Build{Jenkins: &Jenkins{Requester: r}, Raw: new(BuildResponse), Base: "/job/a/7"}
+
+	run, err := build.GetPipelineRun()
+	assert.Nil(t, err)
+	assert.True(t, run.IsRunning())
+	assert.Equal(t, 2*time.Second, run.GetPauseDuration())
+
+	stages, err := run.GetStages()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stages))
+	assert.False(t, stages[0].IsParallel())
+	assert.Equal(t, "Tests", stages[1].GetName())
+	assert.True(t, stages[1].IsParallel())
+	assert.Equal(t, 2, len(stages[1].Branches))
+	assert.Equal(t, "Unit", stages[1].Branches[1].GetName())
+	assert.Equal(t, "tests failed", stages[1].Branches[1].GetError())
+
+	steps, err := stages[0].GetSteps()
+	assert.Nil(t, err)
+	assert.Equal(t, "make test", steps[1].GetDescription())
+	log, err := stages[0].GetLog()
+	assert.Nil(t, err)
+	assert.Equal(t, "compiling\ntesting\n", log)
+
+	steps, err = stages[1].Branches[0].GetSteps()
+	assert.Nil(t, err)
+	_, err = steps[0].GetLog()
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
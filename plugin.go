@@ -15,6 +15,7 @@
 package gojenkins
 
 import (
+	"context"
 	"strconv"
 )
 
@@ -63,11 +64,18 @@ func (p *Plugins) Contains(name string) *Plugin {
 	return nil
 }
 
-func (p *Plugins) Poll() (int, error) {
+func (p *Plugins) Poll(options ...interface{}) (int, error) {
+	return p.PollContext(context.Background(), options...)
+}
+
+func (p *Plugins) PollContext(ctx context.Context, options ...interface{}) (int, error) {
 	qr := map[string]string{
 		"depth": strconv.Itoa(p.Depth),
 	}
-	response, err := p.Jenkins.Requester.GetJSON(p.Base, p.Raw, qr)
+	for k, v := range pollQuery(options) {
+		qr[k] = v
+	}
+	response, err := p.Jenkins.Requester.GetJSONContext(ctx, p.Base, p.Raw, qr)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,101 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package promhook exports Prometheus metrics for the requests a gojenkins
+// client sends:
+//
+//	collector := promhook.NewCollector("")
+//	prometheus.MustRegister(collector)
+//	jenkins, err := gojenkins.NewClient(url, gojenkins.WithMiddleware(collector.Middleware()))
+//
+// Requests are labelled with the HTTP method and the endpoint template, e.g.
+// "/job/{name}/{number}/api/json", so the number of series stays bounded.
+package promhook
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/appscode/gojenkins"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector counts requests by status code and observes their latency.
+type Collector struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// NewCollector creates a collector whose metrics are prefixed with namespace,
+// or "jenkins_client" if it is empty.
+func NewCollector(namespace string) *Collector {
+	if namespace == "" {
+		namespace = "jenkins_client"
+	}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests sent to Jenkins, by method, endpoint and status code.",
+		}, []string{"method", "endpoint", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Time until the response headers from Jenkins arrived, by method and endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "requests_in_flight",
+			Help:      "Requests to Jenkins waiting for a response.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.inFlight.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.inFlight.Collect(ch)
+}
+
+// Middleware records every request passing through it. Requests that failed
+// without a response are counted with code "error".
+func (c *Collector) Middleware() gojenkins.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return gojenkins.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint := gojenkins.RequestEndpoint(req)
+			c.inFlight.Inc()
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			c.inFlight.Dec()
+			c.duration.WithLabelValues(req.Method, endpoint).Observe(time.Since(start).Seconds())
+			code := "error"
+			if err == nil {
+				code = strconv.Itoa(resp.StatusCode)
+			}
+			c.requests.WithLabelValues(req.Method, endpoint, code).Inc()
+			return resp, err
+		})
+	}
+}
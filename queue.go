@@ -15,6 +15,7 @@
 package gojenkins
 
 import (
+	"context"
 	"strconv"
 )
 
@@ -86,15 +87,23 @@ func (q *Queue) GetTasksForJob(name string) []*Task {
 }
 
 func (q *Queue) CancelTask(id int64) (bool, error) {
+	return q.CancelTaskContext(context.Background(), id)
+}
+
+func (q *Queue) CancelTaskContext(ctx context.Context, id int64) (bool, error) {
 	task := q.GetTaskById(id)
-	return task.Cancel()
+	return task.CancelContext(ctx)
 }
 
 func (t *Task) Cancel() (bool, error) {
+	return t.CancelContext(context.Background())
+}
+
+func (t *Task) CancelContext(ctx context.Context) (bool, error) {
 	qr := map[string]string{
 		"id": strconv.FormatInt(t.Raw.ID, 10),
 	}
-	response, err := t.Jenkins.Requester.Post(t.Jenkins.GetQueueUrl()+"/cancelItem", nil, t.Raw, qr)
+	response, err := t.Jenkins.Requester.PostContext(ctx, t.Jenkins.GetQueueUrl()+"/cancelItem", nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
@@ -102,7 +111,11 @@ func (t *Task) Cancel() (bool, error) {
 }
 
 func (t *Task) GetJob() (*Job, error) {
-	return t.Jenkins.GetJob(t.Raw.Task.Name)
+	return t.GetJobContext(context.Background())
+}
+
+func (t *Task) GetJobContext(ctx context.Context) (*Job, error) {
+	return t.Jenkins.GetJobContext(ctx, FullNameFromPath(t.Raw.Task.URL))
 }
 
 func (t *Task) GetWhy() string {
@@ -127,8 +140,14 @@ func (t *Task) GetCauses() []map[string]interface{} {
 	return nil
 }
 
-func (q *Queue) Poll() (int, error) {
-	response, err := q.Jenkins.Requester.GetJSON(q.Base, q.Raw, nil)
+// Poll for current data. Optional parameters - a *Tree selecting the fields
+// to fetch, or the depth as int.
+func (q *Queue) Poll(options ...interface{}) (int, error) {
+	return q.PollContext(context.Background(), options...)
+}
+
+func (q *Queue) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := q.Jenkins.Requester.GetJSONContext(ctx, q.Base, q.Raw, pollQuery(options))
 	if err != nil {
 		return 0, err
 	}
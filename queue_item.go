@@ -0,0 +1,224 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrQueueItemCancelled is returned by QueueItem.WaitForBuild if the item was
+// cancelled before a build started.
+var ErrQueueItemCancelled = errors.New("jenkins: queue item cancelled")
+
+// State of a queue item.
+type QueueItemState string
+
+const (
+	// In the quiet period.
+	QueueItemWaiting QueueItemState = "waiting"
+	// Waiting for another build or resource, see QueueItem.Why.
+	QueueItemBlocked QueueItemState = "blocked"
+	// Waiting for an executor.
+	QueueItemBuildable QueueItemState = "buildable"
+	// Left the queue, the build has started.
+	QueueItemLeft QueueItemState = "left"
+	// Left the queue without a build.
+	QueueItemCancelled QueueItemState = "cancelled"
+)
+
+// How often WaitForBuild polls the queue item unless PollInterval is set.
+const defaultQueuePollInterval = time.Second
+
+// QueueItem is a build request waiting in the queue, as returned by
+// Job.Trigger. Jenkins forgets items a few minutes after they left the queue.
+type QueueItem struct {
+	Jenkins *Jenkins
+	ID      int64
+	Base    string
+	Raw     *queueItemResponse
+	// Time between two polls in WaitForBuild.
+	PollInterval time.Duration
+}
+
+type queueItemResponse struct {
+	taskResponse
+	Class      string `json:"_class"`
+	Cancelled  bool   `json:"cancelled"`
+	Executable *struct {
+		Number int64  `json:"number"`
+		URL    string `json:"url"`
+	} `json:"executable"`
+}
+
+func (j *Jenkins) newQueueItem(id int64) *QueueItem {
+	return &QueueItem{Jenkins: j, ID: id, Base: "/queue/item/" + strconv.FormatInt(id, 10), Raw: new(queueItemResponse)}
+}
+
+func (j *Jenkins) GetQueueItem(id int64) (*QueueItem, error) {
+	return j.GetQueueItemContext(context.Background(), id)
+}
+
+func (j *Jenkins) GetQueueItemContext(ctx context.Context, id int64) (*QueueItem, error) {
+	item := j.newQueueItem(id)
+	if _, err := item.PollContext(ctx); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetQueueItem returns a handle to follow the task after it leaves the queue.
+func (t *Task) GetQueueItem() *QueueItem {
+	item := t.Jenkins.newQueueItem(t.Raw.ID)
+	item.Raw.taskResponse = *t.Raw
+	return item
+}
+
+// Poll for current data. Optional parameters - a *Tree selecting the fields
+// to fetch, or the depth as int.
+func (q *QueueItem) Poll(options ...interface{}) (int, error) {
+	return q.PollContext(context.Background(), options...)
+}
+
+func (q *QueueItem) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := q.Jenkins.Requester.GetJSONContext(ctx, q.Base, q.Raw, pollQuery(options))
+	if err != nil {
+		return 0, err
+	}
+	return response.StatusCode, nil
+}
+
+func (q *QueueItem) GetState() QueueItemState {
+	switch {
+	case q.Raw.Cancelled:
+		return QueueItemCancelled
+	case q.Raw.Executable != nil || strings.HasSuffix(q.Raw.Class, "$LeftItem"):
+		return QueueItemLeft
+	case q.Raw.Blocked:
+		return QueueItemBlocked
+	case q.Raw.Buildable:
+		return QueueItemBuildable
+	}
+	return QueueItemWaiting
+}
+
+// GetWhy returns why the item is still in the queue.
+func (q *QueueItem) GetWhy() string {
+	return q.Raw.Why
+}
+
+// GetBuildNumber returns the number of the build started for the item, or 0
+// if it has not started yet.
+func (q *QueueItem) GetBuildNumber() int64 {
+	if q.Raw.Executable == nil {
+		return 0
+	}
+	return q.Raw.Executable.Number
+}
+
+// WaitForBuild polls the queue item until its build has started and returns
+// the build. It fails with ErrQueueItemCancelled if the item was cancelled,
+// or with the error of ctx.
+func (q *QueueItem) WaitForBuild(ctx context.Context) (*Build, error) {
+	interval := q.PollInterval
+	if interval <= 0 {
+		interval = defaultQueuePollInterval
+	}
+	ctx = WithoutCache(ctx)
+	for {
+		if _, err := q.PollContext(ctx); err != nil {
+			return nil, err
+		}
+		switch q.GetState() {
+		case QueueItemCancelled:
+			return nil, ErrQueueItemCancelled
+		case QueueItemLeft:
+			if q.Raw.Executable != nil {
+				return q.getBuild(ctx)
+			}
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (q *QueueItem) getBuild(ctx context.Context) (*Build, error) {
+	job := &Job{Jenkins: q.Jenkins, Raw: new(JobResponse), Base: q.Jenkins.endpoint(q.Raw.Task.URL)}
+	build := &Build{Jenkins: q.Jenkins, Job: job, Raw: new(BuildResponse), Depth: 1, Base: q.Jenkins.endpoint(q.Raw.Executable.URL)}
+	if _, err := build.PollContext(ctx); err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+// Trigger a build and return the queue item that tracks it. params are sent
+// to buildWithParameters, jobs with parameters use their defaults for the
+// ones left out. If the job is already queued, Jenkins returns that item.
+func (j *Job) Trigger(params map[string]string) (*QueueItem, error) {
+	return j.TriggerContext(context.Background(), params)
+}
+
+func (j *Job) TriggerContext(ctx context.Context, params map[string]string) (*QueueItem, error) {
+	endpoint := "/build"
+	if len(params) > 0 {
+		endpoint = "/buildWithParameters"
+	} else {
+		parameters, err := j.GetParametersContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(parameters) > 0 {
+			endpoint = "/buildWithParameters"
+		}
+	}
+	data := url.Values{}
+	for k, v := range params {
+		data.Set(k, v)
+	}
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.Base+endpoint, strings.NewReader(data.Encode()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := queueItemID(resp)
+	if err != nil {
+		return nil, err
+	}
+	return j.Jenkins.newQueueItem(id), nil
+}
+
+// The id of the queue item a build request created, from the Location header.
+func queueItemID(resp *http.Response) (int64, error) {
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		return 0, newAPIError(resp)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return 0, errors.New("Don't have key \"Location\" in response of header")
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(path.Base(u.Path), 10, 64)
+}
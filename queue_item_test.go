@@ -0,0 +1,60 @@
+package gojenkins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigger(t *testing.T) {
+	polls := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/team/job/app/buildWithParameters":
+			assert.Equal(t, "v1.2", req.FormValue("VERSION"))
+			w.Header().Set("Location", server.URL+"/queue/item/17/")
+			w.WriteHeader(http.StatusCreated)
+		case "/queue/item/17/api/json":
+			polls++
+			task := `"task":{"name":"app","url":"` + server.URL + `/job/team/job/app/"}`
+			switch polls {
+			case 1:
+				w.Write([]byte(`{"_class":"hudson.model.Queue$BlockedItem","id":17,"blocked":true,"why":"Waiting for next available executor",` + task + `}`))
+			default:
+				w.Write([]byte(`{"_class":"hudson.model.Queue$LeftItem","id":17,` + task + `,"executable":{"number":42,"url":"` + server.URL + `/job/team/job/app/42/"}}`))
+			}
+		case "/queue/item/18/api/json":
+			w.Write([]byte(`{"_class":"hudson.model.Queue$LeftItem","id":18,"cancelled":true}`))
+		case "/job/team/job/app/42/api/json":
+			w.Write([]byte(`{"number":42,"building":true}`))
+		}
+	}))
+	defer server.Close()
+	jenkins := &Jenkins{Server: server.URL, Requester: &Requester{Base: server.URL, Client: server.Client()}}
+	job := &Job{Jenkins: jenkins, Raw: new(JobResponse), Base: JobPath("team/app")}
+
+	item, err := job.Trigger(map[string]string{"VERSION": "v1.2"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(17), item.ID)
+	item.PollInterval = time.Millisecond
+	_, err = item.Poll()
+	assert.Nil(t, err)
+	assert.Equal(t, QueueItemBlocked, item.GetState())
+
+	build, err := item.WaitForBuild(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, QueueItemLeft, item.GetState())
+	assert.Equal(t, int64(42), build.GetBuildNumber())
+	assert.Equal(t, "team/app", build.Job.GetFullName())
+
+	item, err = jenkins.GetQueueItem(18)
+	assert.Nil(t, err)
+	assert.Equal(t, QueueItemCancelled, item.GetState())
+	_, err = item.WaitForBuild(context.Background())
+	assert.Equal(t, ErrQueueItemCancelled, err)
+}
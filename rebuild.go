@@ -0,0 +1,225 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Rebuild triggers the job of the build again, with the parameters of the
+// build. Files of file parameters are downloaded and uploaded again. Jenkins
+// does not report the values of password parameters, the job's defaults are
+// used for those.
+func (b *Build) Rebuild() (*QueueItem, error) {
+	return b.RebuildContext(context.Background())
+}
+
+func (b *Build) RebuildContext(ctx context.Context) (*QueueItem, error) {
+	params := make(map[string]string)
+	var files []parameter
+	for _, p := range b.GetParameters() {
+		switch {
+		case strings.HasSuffix(p.Class, ".FileParameterValue"):
+			files = append(files, p)
+		case p.hasValue:
+			params[p.Name] = p.Value
+		}
+	}
+	if len(files) == 0 {
+		return b.Job.TriggerContext(ctx, params)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range params {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range files {
+		if err := b.copyFileParameter(ctx, writer, p); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	ar := NewAPIRequest("POST", b.Job.Base+"/buildWithParameters", body)
+	if err := b.Jenkins.Requester.SetCrumbContext(ctx, ar); err != nil {
+		return nil, err
+	}
+	ar.SetHeader("Content-Type", writer.FormDataContentType())
+	ar.Suffix = ""
+	resp, err := b.Jenkins.Requester.DoContext(ctx, ar, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := queueItemID(resp)
+	if err != nil {
+		return nil, err
+	}
+	return b.Jenkins.newQueueItem(id), nil
+}
+
+// Add the file uploaded for the file parameter p to the form.
+func (b *Build) copyFileParameter(ctx context.Context, writer *multipart.Writer, p parameter) error {
+	if p.OriginalFileName == "" {
+		return fmt.Errorf("jenkins: file name of parameter %s is unknown", p.Name)
+	}
+	// The file is only served without a trailing slash, which endpoints get.
+	ar := NewAPIRequest("GET", b.Base+"/parameters/parameter/"+url.PathEscape(p.Name)+"/", nil)
+	ar.Suffix = url.PathEscape(p.OriginalFileName)
+	var body io.ReadCloser
+	if _, err := b.Jenkins.Requester.DoContext(ctx, ar, &body); err != nil {
+		return err
+	}
+	defer body.Close()
+	part, err := writer.CreateFormFile(p.Name, p.OriginalFileName)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, body)
+	return err
+}
+
+// Replay runs a Pipeline build again with a changed Jenkinsfile. loadedScripts
+// replaces scripts loaded with the load step, keyed by their class name, e.g.
+// "Script1". Scripts left out are run as they were.
+//
+// Jenkins does not report the queue item of a replay, so the result is a best
+// effort: the first replay of this build that was queued or started after
+// the request, found in the queue or among the recent builds of the job. If
+// someone else replays the same build at the same time, their replay may be
+// returned.
+func (b *Build) Replay(mainScript string, loadedScripts map[string]string) (*QueueItem, error) {
+	return b.ReplayContext(context.Background(), mainScript, loadedScripts)
+}
+
+func (b *Build) ReplayContext(ctx context.Context, mainScript string, loadedScripts map[string]string) (*QueueItem, error) {
+	form := map[string]string{"mainScript": mainScript}
+	for class, script := range loadedScripts {
+		form[strings.Replace(class, ".", "_", -1)] = script
+	}
+	data := url.Values{}
+	data.Set("mainScript", mainScript)
+	data.Set("json", makeJson(form))
+
+	// The queue and the builds are polled, they must not come from the cache.
+	ctx = WithoutCache(ctx)
+	queued, err := b.queuedReplays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	next := new(struct {
+		NextBuildNumber int64 `json:"nextBuildNumber"`
+	})
+	if _, err := b.Jenkins.Requester.GetJSONContext(ctx, b.Job.Base, next, map[string]string{"tree": "nextBuildNumber"}); err != nil {
+		return nil, err
+	}
+
+	if _, err := b.Jenkins.Requester.PostContext(ctx, b.Base+"/replay/run", strings.NewReader(data.Encode()), nil, nil); err != nil {
+		return nil, err
+	}
+	return b.findReplay(ctx, queued, next.NextBuildNumber)
+}
+
+var replayCausesTree = Fields().Nested("causes", Fields("_class", "shortDescription", "originalNumber"))
+
+// The ids of the queue items replaying b.
+func (b *Build) queuedReplays(ctx context.Context) (map[int64]bool, error) {
+	queue := new(queueResponse)
+	tree := Fields().Nested("items", Fields("id").Nested("task", Fields("url")).Nested("actions", replayCausesTree))
+	if _, err := b.Jenkins.Requester.GetJSONContext(ctx, "/queue", queue, map[string]string{"tree": tree.String()}); err != nil {
+		return nil, err
+	}
+	ids := make(map[int64]bool)
+	for _, item := range queue.Items {
+		if b.Jenkins.endpoint(item.Task.URL) == b.Job.Base && b.replayedBy(item.Actions) {
+			ids[item.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// Find the replay of b that is not one of the items queued before, and was
+// not started before build nextBuild.
+func (b *Build) findReplay(ctx context.Context, queued map[int64]bool, nextBuild int64) (*QueueItem, error) {
+	now, err := b.queuedReplays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	for item := range now {
+		if !queued[item] && (id == 0 || item < id) {
+			id = item
+		}
+	}
+	if id > 0 {
+		return b.Jenkins.newQueueItem(id), nil
+	}
+
+	// The replay may have left the queue already.
+	job := new(struct {
+		Builds []struct {
+			Number  int64           `json:"number"`
+			QueueID int64           `json:"queueId"`
+			Actions []generalAction `json:"actions"`
+		} `json:"builds"`
+	})
+	tree := Fields().Nested("builds", Fields("number", "queueId").Nested("actions", replayCausesTree)).Range(0, 10)
+	if _, err := b.Jenkins.Requester.GetJSONContext(ctx, b.Job.Base, job, map[string]string{"tree": tree.String()}); err != nil {
+		return nil, err
+	}
+	// Builds are listed newest first.
+	for i := len(job.Builds) - 1; i >= 0; i-- {
+		build := job.Builds[i]
+		if build.Number >= nextBuild && !queued[build.QueueID] && b.replayedBy(build.Actions) {
+			return b.Jenkins.newQueueItem(build.QueueID), nil
+		}
+	}
+	return nil, errors.New("jenkins: replayed build not found")
+}
+
+// Whether actions have a cause replaying b. The number of the replayed build
+// is only part of the description of the cause, "Replayed #7".
+func (b *Build) replayedBy(actions []generalAction) bool {
+	number := strconv.FormatInt(b.Raw.Number, 10)
+	for _, a := range actions {
+		for _, cause := range a.Causes {
+			if class, _ := cause["_class"].(string); !strings.HasSuffix(class, ".ReplayCause") {
+				continue
+			}
+			if original, ok := cause["originalNumber"].(float64); ok {
+				if int64(original) == b.Raw.Number {
+					return true
+				}
+				continue
+			}
+			if description, _ := cause["shortDescription"].(string); strings.HasSuffix(description, "#"+number) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuildAndReplay(t *testing.T) {
+	const replayCause = "org.jenkinsci.plugins.workflow.cps.replay.ReplayCause"
+	var server *httptest.Server
+	files, queued, replayed := false, true, false
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/a/7/parameters/parameter/CONFIG/app.yaml":
+			w.Write([]byte("replicas: 3\n"))
+		case "/job/a/buildWithParameters":
+			if req.FormValue("DEBUG") != "true" {
+				t.Errorf("DEBUG = %q", req.FormValue("DEBUG"))
+			}
+			_, hasPassword := req.Form["TOKEN"]
+			assert.False(t, hasPassword)
+			if files {
+				file, _, err := req.FormFile("CONFIG")
+				if assert.Nil(t, err) {
+					content, _ := ioutil.ReadAll(file)
+					assert.Equal(t, "replicas: 3\n", string(content))
+				}
+			}
+			w.Header().Set("Location", server.URL+"/queue/item/21/")
+			w.WriteHeader(http.StatusCreated)
+		case "/job/a/7/replay/run":
+			var form map[string]string
+			assert.Nil(t, json.Unmarshal([]byte(req.FormValue("json")), &form))
+			assert.Equal(t, map[string]string{"mainScript": "echo 'hi'", "lib_Utils": "return this"}, form)
+			replayed = true
+			w.WriteHeader(http.StatusFound)
+		case "/queue/api/json":
+			replay := func(id int, job string, number int) string {
+				return `{"id":` + strconv.Itoa(id) + `,"task":{"url":"` + server.URL + `/job/` + job + `/"},"actions":[{"causes":[{"_class":"` + replayCause + `","shortDescription":"Replayed #` + strconv.Itoa(number) + `"}]}]}`
+			}
+			switch {
+			case !queued:
+				w.Write([]byte(`{"items":[]}`))
+			case !replayed:
+				w.Write([]byte(`{"items":[` + replay(28, "a", 7) + `]}`))
+			default:
+				w.Write([]byte(`{"items":[` + replay(28, "a", 7) + `,` + replay(30, "a", 7) + `,` + replay(31, "b", 7) + `,` + replay(32, "a", 6) + `]}`))
+			}
+		case "/job/a/api/json":
+			if req.FormValue("tree") == "nextBuildNumber" {
+				w.Write([]byte(`{"nextBuildNumber":8}`))
+				return
+			}
+			w.Write([]byte(`{"builds":[
+				{"number":9,"queueId":33,"actions":[{"causes":[{"_class":"` + replayCause + `","shortDescription":"Replayed #6"}]}]},
+				{"number":8,"queueId":29,"actions":[{"causes":[{"_class":"` + replayCause + `","shortDescription":"Replayed #7"}]}]},
+				{"number":5,"queueId":20,"actions":[{"causes":[{"_class":"` + replayCause + `","shortDescription":"Replayed #7"}]}]}]}`))
+		}
+	}))
+	defer server.Close()
+	jenkins := &Jenkins{Server: server.URL, Requester: &Requester{Base: server.URL, Client: server.Client()}}
+	job := &Job{Jenkins: jenkins, Raw: new(JobResponse), Base: "/job/a"}
+	build := &Build{Jenkins: jenkins, Job: job, Raw: new(BuildResponse), Base: "/job/a/7"}
+	assert.Nil(t, json.Unmarshal([]byte(`{"number":7,"actions":[{"parameters":[
+		{"_class":"hudson.model.BooleanParameterValue","name":"DEBUG","value":true},
+		{"_class":"hudson.model.PasswordParameterValue","name":"TOKEN"}]}]}`), build.Raw))
+	assert.Equal(t, "true", build.GetParameters()[0].Value)
+
+	item, err := build.Rebuild()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), item.ID)
+
+	build.Raw.Actions[0].Parameters = append(build.Raw.Actions[0].Parameters,
+		parameter{Class: "hudson.model.FileParameterValue", Name: "CONFIG", OriginalFileName: "app.yaml"})
+	files = true
+	item, err = build.Rebuild()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), item.ID)
+
+	item, err = build.Replay("echo 'hi'", map[string]string{"lib.Utils": "return this"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(30), item.ID)
+	queued, replayed = false, false
+	item, err = build.Replay("echo 'hi'", map[string]string{"lib.Utils": "return this"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(29), item.ID)
+}
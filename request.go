@@ -16,6 +16,7 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,10 +24,13 @@ import (
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Request Methods
@@ -44,6 +48,23 @@ func (ar *APIRequest) SetHeader(key string, value string) *APIRequest {
 	return ar
 }
 
+// A copy of ar asking Jenkins to only send the resource if it changed since
+// the entry was cached.
+func (ar *APIRequest) conditional(entry *CacheEntry) *APIRequest {
+	c := *ar
+	c.Headers = ar.Headers.Clone()
+	if c.Headers == nil {
+		c.Headers = http.Header{}
+	}
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		c.Headers.Set("If-None-Match", etag)
+	}
+	if modified := entry.Header.Get("Last-Modified"); modified != "" {
+		c.Headers.Set("If-Modified-Since", modified)
+	}
+	return &c
+}
+
 func NewAPIRequest(method string, endpoint string, payload io.Reader) *APIRequest {
 	var headers = http.Header{}
 	var suffix string
@@ -54,79 +75,112 @@ func NewAPIRequest(method string, endpoint string, payload io.Reader) *APIReques
 type Requester struct {
 	Base      string
 	BasicAuth *BasicAuth
+	Auth      Authenticator
 	Client    *http.Client
 	CACert    []byte
 	SslVerify bool
+	Retry     *RetryPolicy
+	UserAgent string
+	Logger    Logger
+	// Log every request and response at debug level, without credentials.
+	DebugHTTP bool
+	// Wrapped around the transport of Client, see Use.
+	Middleware []Middleware
+	// Bounds the rate and concurrency of requests, see WithPriority.
+	Limiter *Limiter
+	// Keeps api/json responses, see Cache.
+	Cache *Cache
+
+	crumbMu    sync.Mutex
+	crumb      *crumb
+	crumbFetch *crumbFetch
 }
 
-func (r *Requester) SetCrumb(ar *APIRequest) error {
-	crumbData := map[string]string{}
-	response, _ := r.GetJSON("/crumbIssuer/api/json", &crumbData, nil)
-
-	if response.StatusCode == 200 && crumbData["crumbRequestField"] != "" {
-		ar.SetHeader(crumbData["crumbRequestField"], crumbData["crumb"])
-	}
-
-	return nil
+func (r *Requester) PostJSON(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostJSONContext(context.Background(), endpoint, payload, responseStruct, querystring)
 }
 
-func (r *Requester) PostJSON(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+func (r *Requester) PostJSONContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
+	if err := r.SetCrumbContext(ctx, ar); err != nil {
 		return nil, err
 	}
 	ar.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	ar.Suffix = "api/json"
-	return r.Do(ar, &responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) Post(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostContext(context.Background(), endpoint, payload, responseStruct, querystring)
+}
+
+func (r *Requester) PostContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
+	if err := r.SetCrumbContext(ctx, ar); err != nil {
 		return nil, err
 	}
 	ar.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	ar.Suffix = ""
-	return r.Do(ar, &responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) PostFiles(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string, files []string) (*http.Response, error) {
+	return r.PostFilesContext(context.Background(), endpoint, payload, responseStruct, querystring, files)
+}
+
+func (r *Requester) PostFilesContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string, files []string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
+	if err := r.SetCrumbContext(ctx, ar); err != nil {
 		return nil, err
 	}
-	return r.Do(ar, &responseStruct, querystring, files)
+	return r.DoContext(ctx, ar, responseStruct, querystring, files)
 }
 
 func (r *Requester) PostXML(endpoint string, xml string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostXMLContext(context.Background(), endpoint, xml, responseStruct, querystring)
+}
+
+func (r *Requester) PostXMLContext(ctx context.Context, endpoint string, xml string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	payload := bytes.NewBuffer([]byte(xml))
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
+	if err := r.SetCrumbContext(ctx, ar); err != nil {
 		return nil, err
 	}
 	ar.SetHeader("Content-Type", "application/xml")
 	ar.Suffix = ""
-	return r.Do(ar, &responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) GetJSON(endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.GetJSONContext(context.Background(), endpoint, responseStruct, querystring)
+}
+
+func (r *Requester) GetJSONContext(ctx context.Context, endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
 	ar.SetHeader("Content-Type", "application/json")
 	ar.Suffix = "api/json"
-	return r.Do(ar, &responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) GetXML(endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.GetXMLContext(context.Background(), endpoint, responseStruct, querystring)
+}
+
+func (r *Requester) GetXMLContext(ctx context.Context, endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
 	ar.SetHeader("Content-Type", "application/xml")
 	ar.Suffix = ""
-	return r.Do(ar, responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) Get(endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.GetContext(context.Background(), endpoint, responseStruct, querystring)
+}
+
+func (r *Requester) GetContext(ctx context.Context, endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
 	ar.Suffix = ""
-	return r.Do(ar, responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) SetClient(client *http.Client) *Requester {
@@ -144,15 +198,47 @@ func (r *Requester) parseQueryString(queries map[string]string) string {
 	return output
 }
 
-//Add auth on redirect if required.
+func (r *Requester) newHTTPClient(transport http.RoundTripper) *http.Client {
+	cookies, _ := cookiejar.New(nil)
+	return &http.Client{
+		Transport: transport,
+		Jar:       cookies,
+		// Function to add auth on redirect.
+		CheckRedirect: r.redirectPolicyFunc,
+	}
+}
+
+// Add auth on redirect if required.
+// The http.Client copies the headers of the first request to the redirected
+// one, so headers set by the authenticator are removed when leaving the host.
 func (r *Requester) redirectPolicyFunc(req *http.Request, via []*http.Request) error {
-	if r.BasicAuth != nil {
-		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	auth := r.authenticator()
+	if auth == nil {
+		return nil
+	}
+	if sameOrigin(req, via[0]) {
+		return auth.Authenticate(req)
+	}
+	for _, k := range authHeaderKeys(auth, req.Header) {
+		req.Header.Del(k)
 	}
 	return nil
 }
 
 func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...interface{}) (*http.Response, error) {
+	return r.DoContext(context.Background(), ar, responseStruct, options...)
+}
+
+// DoContext sends ar to Jenkins. The request is bound to ctx, so cancelling ctx
+// or letting its deadline expire aborts the call.
+//
+// Error status codes and responses carrying an X-Error header are returned as
+// *APIError, together with the response whose body has already been closed.
+// Failed attempts are retried according to r.Retry.
+func (r *Requester) DoContext(ctx context.Context, ar *APIRequest, responseStruct interface{}, options ...interface{}) (*http.Response, error) {
 	if !strings.HasSuffix(ar.Endpoint, "/") && ar.Method != "POST" {
 		ar.Endpoint += "/"
 	}
@@ -181,7 +267,8 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 			files = v
 		}
 	}
-	var req *http.Request
+	var payload []byte
+	contentType := ""
 
 	if fileUpload {
 		body := &bytes.Buffer{}
@@ -189,13 +276,13 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 		for _, file := range files {
 			fileData, err := os.Open(file)
 			if err != nil {
-				Error.Println(err.Error())
+				r.logger().Error("Could not open file", "file", file, "error", err)
 				return nil, err
 			}
 
 			part, err := writer.CreateFormFile("file", filepath.Base(file))
 			if err != nil {
-				Error.Println(err.Error())
+				r.logger().Error("Could not attach file", "file", file, "error", err)
 				return nil, err
 			}
 			if _, err = io.Copy(part, fileData); err != nil {
@@ -213,43 +300,148 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 		if err = writer.Close(); err != nil {
 			return nil, err
 		}
-		req, err = http.NewRequest(ar.Method, URL.String(), body)
-		if err != nil {
+		payload = body.Bytes()
+		contentType = writer.FormDataContentType()
+	} else if ar.Payload != nil {
+		// Keep the payload around, so it can be sent again on retry.
+		if payload, err = ioutil.ReadAll(ar.Payload); err != nil {
 			return nil, err
 		}
-		req.Header.Set("Content-Type", writer.FormDataContentType())
-	} else {
+	}
+
+	// The crumb issuer is asked for crumbs by SetCrumb, which caches them per
+	// session already. Its requests need no crumb themselves.
+	crumbIssuer := ar.Endpoint == "/crumbIssuer/"
+
+	// Only api/json GETs are cached, anything else may change what they return.
+	cacheKey := ""
+	var cached *CacheEntry
+	if r.Cache != nil {
+		if ar.Method == "GET" && ar.Suffix == "api/json" && !crumbIssuer {
+			cacheKey = URL.String()
+			var fresh bool
+			if cached, fresh = r.Cache.lookup(cacheKey); fresh && !noCacheFromContext(ctx) {
+				return r.readResponse(cached.response(nil), responseStruct)
+			}
+			if cached != nil {
+				ar = ar.conditional(cached)
+			}
+		} else if ar.Method != "GET" && ar.Method != "HEAD" {
+			defer r.Cache.invalidate(r.Base, ar.Endpoint)
+		}
+	}
 
-		req, err = http.NewRequest(ar.Method, URL.String(), ar.Payload)
-		if err != nil {
+	var response *http.Response
+	for attempt := 1; ; attempt++ {
+		response, err = r.send(ctx, ar, URL.String(), payload, contentType)
+		crumbRejected := errors.Is(err, ErrCrumbInvalid)
+		if crumbRejected && crumbIssuer {
+			break
+		}
+		if crumbRejected {
+			r.InvalidateCrumb()
+		}
+		wait, retry := r.Retry.next(ar.Method, attempt, response, err)
+		if !retry {
+			break
+		}
+		if crumbRejected {
+			if err := r.SetCrumbContext(ctx, ar); err != nil {
+				return nil, err
+			}
+		}
+		if err := sleepContext(ctx, wait); err != nil {
 			return nil, err
 		}
 	}
+	if err != nil {
+		return response, err
+	}
 
-	if r.BasicAuth != nil {
-		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
+	if cacheKey != "" {
+		switch {
+		case response.StatusCode == http.StatusNotModified && cached != nil:
+			response.Body.Close()
+			response = cached.response(response.Request)
+		case response.StatusCode == http.StatusOK:
+			if err := r.Cache.store(cacheKey, response); err != nil {
+				return response, err
+			}
+		}
 	}
+	return r.readResponse(response, responseStruct)
+}
 
-	for k := range ar.Headers {
-		req.Header.Add(k, ar.Headers.Get(k))
+// Decode the body into responseStruct: nil drains it, *string reads it as is,
+// *io.ReadCloser hands it over unread, anything else is decoded from JSON.
+func (r *Requester) readResponse(response *http.Response, responseStruct interface{}) (*http.Response, error) {
+	switch v := responseStruct.(type) {
+	case nil:
+		defer response.Body.Close()
+		io.Copy(ioutil.Discard, response.Body)
+		return response, nil
+	case *io.ReadCloser:
+		*v = response.Body
+		return response, nil
+	case *string:
+		return r.ReadRawResponse(response, responseStruct)
+	default:
+		return r.ReadJSONResponse(response, responseStruct)
 	}
+}
 
-	if response, err := r.Client.Do(req); err != nil {
+// Make a single attempt at sending the request.
+func (r *Requester) send(ctx context.Context, ar *APIRequest, URL string, payload []byte, contentType string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(withEndpoint(ctx, ar.Endpoint+ar.Suffix), ar.Method, URL, body)
+	if err != nil {
 		return nil, err
-	} else {
-		errorText := response.Header.Get("X-Error")
-		if errorText != "" {
-			return nil, errors.New(errorText)
-		}
-		switch responseStruct.(type) {
-		case *string:
-			return r.ReadRawResponse(response, responseStruct)
-		default:
-			return r.ReadJSONResponse(response, responseStruct)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.UserAgent != "" {
+		req.Header.Set("User-Agent", r.UserAgent)
+	}
+
+	if auth := r.authenticator(); auth != nil {
+		if err := auth.Authenticate(req); err != nil {
+			return nil, err
 		}
+	}
 
+	for k := range ar.Headers {
+		req.Header.Add(k, ar.Headers.Get(k))
 	}
 
+	release := func() {}
+	if r.Limiter != nil {
+		if release, err = r.Limiter.Wait(ctx, priorityFromContext(ctx)); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.DebugHTTP {
+		r.logRequest(req)
+	}
+	start := time.Now()
+	response, err := r.httpClient().Do(req)
+	if r.DebugHTTP {
+		r.logResponse(req, response, err, time.Since(start))
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+	response.Body = &releaseOnClose{ReadCloser: response.Body, release: release}
+	if response.StatusCode >= 400 || response.Header.Get("X-Error") != "" {
+		defer response.Body.Close()
+		return response, newAPIError(response)
+	}
+	return response, nil
 }
 
 func (r *Requester) ReadRawResponse(response *http.Response, responseStruct interface{}) (*http.Response, error) {
@@ -271,6 +463,9 @@ func (r *Requester) ReadRawResponse(response *http.Response, responseStruct inte
 func (r *Requester) ReadJSONResponse(response *http.Response, responseStruct interface{}) (*http.Response, error) {
 	defer response.Body.Close()
 
-	json.NewDecoder(response.Body).Decode(responseStruct)
+	// An empty body is not an error, POST endpoints often return nothing.
+	if err := json.NewDecoder(response.Body).Decode(responseStruct); err != nil && err != io.EOF {
+		return response, fmt.Errorf("jenkins: could not decode response: %w", err)
+	}
 	return response, nil
 }
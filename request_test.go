@@ -0,0 +1,59 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRequester(handler http.HandlerFunc) (*Requester, func()) {
+	server := httptest.NewServer(handler)
+	r := &Requester{Base: server.URL, Client: server.Client()}
+	return r, server.Close
+}
+
+func TestAPIErrors(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/missing/api/json":
+			http.NotFound(w, req)
+		case "/job/locked/api/json":
+			w.WriteHeader(http.StatusForbidden)
+		case "/job/crumb/build":
+			http.Error(w, "No valid crumb was included in the request", http.StatusForbidden)
+		case "/createItem":
+			w.Header().Set("X-Error", "A job already exists with the name 'foo'")
+			w.WriteHeader(http.StatusBadRequest)
+		case "/job/broken/api/json":
+			w.Write([]byte("<html>"))
+		}
+	})
+	defer done()
+
+	_, err := r.GetJSON("/job/missing", new(JobResponse), nil)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrForbidden))
+
+	_, err = r.GetJSON("/job/locked", new(JobResponse), nil)
+	assert.True(t, errors.Is(err, ErrForbidden))
+	assert.False(t, errors.Is(err, ErrCrumbInvalid))
+
+	ar := NewAPIRequest("POST", "/job/crumb/build", nil)
+	_, err = r.Do(ar, nil)
+	assert.True(t, errors.Is(err, ErrCrumbInvalid))
+	assert.False(t, errors.Is(err, ErrForbidden))
+
+	ar = NewAPIRequest("POST", "/createItem", nil)
+	_, err = r.Do(ar, nil)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "POST", apiErr.Method)
+	assert.Equal(t, "A job already exists with the name 'foo'", apiErr.JenkinsError)
+
+	_, err = r.GetJSON("/job/broken", new(JobResponse), nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,141 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the Requester retries failed requests.
+// A nil policy on the Requester means every request is sent exactly once.
+//
+// Requests are retried on transport errors and on the status codes listed in
+// RetryableStatusCodes. Non-idempotent requests (POST) are only retried when
+// they could not have reached Jenkins, i.e. the connection could not be
+// established or the crumb was rejected, unless RetryNonIdempotent is set.
+// A request whose crumb was rejected is retried right away with a fresh crumb.
+type RetryPolicy struct {
+	// Total number of attempts, including the first one.
+	MaxAttempts int
+	// Backoff before the first retry. It doubles with every attempt, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Status codes that are worth another attempt.
+	RetryableStatusCodes []int
+	// Retry POST requests on any retryable failure as well.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a policy that makes up to 4 attempts and rides out
+// the 502/503/504 responses Jenkins and its proxies return while restarting.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          4,
+		MinBackoff:           500 * time.Millisecond,
+		MaxBackoff:           10 * time.Second,
+		RetryableStatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// Decide whether the outcome of an attempt should be retried, and how long to
+// wait before doing so.
+func (p *RetryPolicy) next(method string, attempt int, response *http.Response, err error) (time.Duration, bool) {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	if errors.Is(err, ErrCrumbInvalid) {
+		return 0, true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if !p.retryableStatus(apiErr.StatusCode) || !p.idempotent(method) {
+			return 0, false
+		}
+		return p.backoff(attempt, response), true
+	}
+	if !p.idempotent(method) && !isDialError(err) {
+		return 0, false
+	}
+	return p.backoff(attempt, nil), true
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatusCodes {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// Exponential backoff with jitter. A Retry-After header sent by Jenkins takes
+// precedence, but is capped at MaxBackoff as well.
+func (p *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return p.capBackoff(time.Duration(seconds) * time.Second)
+		}
+	}
+	d := p.capBackoff(p.MinBackoff << uint(attempt-1))
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+func (p *RetryPolicy) capBackoff(d time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && (d > p.MaxBackoff || d < 0) {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// The connection could not be established, so the request never reached Jenkins.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
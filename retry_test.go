@@ -0,0 +1,49 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	attempts := map[string]int{}
+	crumbs := 0
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		attempts[req.Method+" "+req.URL.Path]++
+		switch req.URL.Path {
+		case "/crumbIssuer/api/json":
+			crumbs++
+			w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"fresh"}`))
+		case "/restarting/api/json", "/restarting/build":
+			if attempts[req.Method+" "+req.URL.Path] < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{}`))
+		case "/job/crumb/build":
+			if req.Header.Get("Jenkins-Crumb") != "fresh" {
+				http.Error(w, "No valid crumb was included in the request", http.StatusForbidden)
+			}
+		}
+	})
+	defer done()
+	r.Retry = &RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+
+	_, err := r.GetJSON("/restarting", new(JobResponse), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts["GET /restarting/api/json"])
+
+	_, err = r.Do(NewAPIRequest("POST", "/restarting/build", nil), nil)
+	assert.True(t, errors.Is(err, ErrServerError))
+	assert.Equal(t, 1, attempts["POST /restarting/build"])
+
+	ar := NewAPIRequest("POST", "/job/crumb/build", nil)
+	ar.SetHeader("Jenkins-Crumb", "stale")
+	_, err = r.Do(ar, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts["POST /job/crumb/build"])
+	assert.Equal(t, 1, crumbs)
+}
@@ -0,0 +1,93 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// ByteRange selects part of a download, like the HTTP Range header. Start and
+// End are byte offsets, End is inclusive. An End of zero or less reads to the
+// end of the file, so ByteRange{Start: n} skips the first n bytes.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// FromOffset returns the range from offset to the end of the file.
+func FromOffset(offset int64) ByteRange {
+	return ByteRange{Start: offset}
+}
+
+func (br ByteRange) openEnded() bool {
+	return br.End <= 0
+}
+
+func (br ByteRange) String() string {
+	s := "bytes=" + strconv.FormatInt(br.Start, 10) + "-"
+	if !br.openEnded() {
+		s += strconv.FormatInt(br.End, 10)
+	}
+	return s
+}
+
+// Cut the range out of a body sent in full, for endpoints that do not
+// support Range requests.
+func (br ByteRange) apply(response *http.Response) (io.ReadCloser, error) {
+	if response.StatusCode == http.StatusPartialContent {
+		return response.Body, nil
+	}
+	if _, err := io.CopyN(ioutil.Discard, response.Body, br.Start); err != nil && err != io.EOF {
+		response.Body.Close()
+		return nil, err
+	}
+	if br.openEnded() {
+		return response.Body, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(response.Body, br.End-br.Start+1), response.Body}, nil
+}
+
+func (r *Requester) GetStream(endpoint string, querystring map[string]string, rng ...ByteRange) (*http.Response, error) {
+	return r.GetStreamContext(context.Background(), endpoint, querystring, rng...)
+}
+
+// GetStreamContext sends a GET and returns the response without reading the
+// body. The caller must close response.Body. An optional ByteRange limits the
+// body to that part of the resource.
+func (r *Requester) GetStreamContext(ctx context.Context, endpoint string, querystring map[string]string, rng ...ByteRange) (*http.Response, error) {
+	ar := NewAPIRequest("GET", endpoint, nil)
+	ar.Suffix = ""
+	if len(rng) > 0 {
+		ar.SetHeader("Range", rng[0].String())
+	}
+	var body io.ReadCloser
+	response, err := r.DoContext(ctx, ar, &body, querystring)
+	if err != nil {
+		return response, err
+	}
+	if len(rng) > 0 {
+		if response.Body, err = rng[0].apply(response); err != nil {
+			return response, err
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,44 @@
+package gojenkins
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/job/a/1/artifact/app.bin/":
+			http.ServeContent(w, req, "app.bin", time.Time{}, strings.NewReader("0123456789"))
+		case "/job/a/1/consoleText/":
+			w.Write([]byte("Started by user admin\nFinished: SUCCESS\n"))
+		}
+	})
+	defer done()
+	jenkins := &Jenkins{Requester: r}
+	build := &Build{Jenkins: jenkins, Base: "/job/a/1"}
+	artifact := Artifact{Jenkins: jenkins, Build: build, Path: "/job/a/1/artifact/app.bin"}
+
+	read := func(body io.ReadCloser, err error) string {
+		assert.Nil(t, err)
+		defer body.Close()
+		data, err := ioutil.ReadAll(body)
+		assert.Nil(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "0123456789", read(artifact.Open()))
+	assert.Equal(t, "234", read(artifact.Open(ByteRange{Start: 2, End: 4})))
+	assert.Equal(t, "789", read(artifact.Open(ByteRange{Start: 7, End: -1})))
+	assert.Equal(t, "789", read(artifact.Open(ByteRange{Start: 7})))
+	assert.Equal(t, "56789", read(artifact.Open(FromOffset(5))))
+	assert.Equal(t, "bytes=0-", ByteRange{}.String())
+	assert.Equal(t, "Finished: SUCCESS\n", read(build.ConsoleReader(FromOffset(22))))
+	// consoleText ignores the Range header, the range is cut out client side.
+	assert.Equal(t, "Finished", read(build.ConsoleReader(ByteRange{Start: 22, End: 29})))
+}
@@ -0,0 +1,210 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// TestResult is the test report of a build, see Build.GetResultSet.
+// Durations are in seconds.
+type TestResult struct {
+	Duration  float64     `json:"duration"`
+	Empty     bool        `json:"empty"`
+	FailCount int64       `json:"failCount"`
+	PassCount int64       `json:"passCount"`
+	SkipCount int64       `json:"skipCount"`
+	Suites    []TestSuite `json:"suites"`
+}
+
+type TestSuite struct {
+	Cases     []TestCase `json:"cases"`
+	Duration  float64    `json:"duration"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Stderr    string     `json:"stderr"`
+	Stdout    string     `json:"stdout"`
+	Timestamp string     `json:"timestamp"`
+}
+
+type TestCase struct {
+	// Number of builds the test has been failing for, 0 if it passed.
+	Age             int64   `json:"age"`
+	ClassName       string  `json:"className"`
+	Duration        float64 `json:"duration"`
+	ErrorDetails    string  `json:"errorDetails"`
+	ErrorStackTrace string  `json:"errorStackTrace"`
+	// Number of the build the test started failing in, 0 if it passed.
+	FailedSince    int64  `json:"failedSince"`
+	Name           string `json:"name"`
+	Skipped        bool   `json:"skipped"`
+	SkippedMessage string `json:"skippedMessage"`
+	// One of STATUS_PASSED, STATUS_FIXED, RESULT_STATUS_SKIPPED,
+	// RESULT_STATUS_FAILED or STATUS_REGRESSION.
+	Status string `json:"status"`
+	Stderr string `json:"stderr"`
+	Stdout string `json:"stdout"`
+}
+
+// GetFullName returns the class name and the name of the test case.
+func (c *TestCase) GetFullName() string {
+	if c.ClassName == "" {
+		return c.Name
+	}
+	return c.ClassName + "." + c.Name
+}
+
+func (c *TestCase) IsFailed() bool {
+	return c.Status == RESULT_STATUS_FAILED || c.Status == STATUS_REGRESSION
+}
+
+// IsRegression reports whether the test failed for the first time, after it
+// passed in the previous build.
+func (c *TestCase) IsRegression() bool {
+	return c.IsFailed() && c.Age == 1
+}
+
+// Cases calls fn with every test case of every suite, stopping if it returns
+// false.
+func (r *TestResult) Cases(fn func(suite *TestSuite, c *TestCase) bool) {
+	for i := range r.Suites {
+		suite := &r.Suites[i]
+		for j := range suite.Cases {
+			if !fn(suite, &suite.Cases[j]) {
+				return
+			}
+		}
+	}
+}
+
+// Failures returns the failed test cases.
+func (r *TestResult) Failures() []*TestCase {
+	return r.filter(func(c *TestCase) bool { return c.IsFailed() })
+}
+
+// Regressions returns the test cases that failed for the first time.
+func (r *TestResult) Regressions() []*TestCase {
+	return r.filter(func(c *TestCase) bool { return c.IsRegression() })
+}
+
+// FailingFor returns the test cases that failed in at least the last builds
+// builds, i.e. since build FailedSince.
+func (r *TestResult) FailingFor(builds int64) []*TestCase {
+	return r.filter(func(c *TestCase) bool { return c.IsFailed() && c.Age >= builds })
+}
+
+func (r *TestResult) filter(keep func(c *TestCase) bool) []*TestCase {
+	var cases []*TestCase
+	r.Cases(func(_ *TestSuite, c *TestCase) bool {
+		if keep(c) {
+			cases = append(cases, c)
+		}
+		return true
+	})
+	return cases
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	ID        string          `xml:"id,attr,omitempty"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+	Stdout    string          `xml:"system-out,omitempty"`
+	Stderr    string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	Stdout    string        `xml:"system-out,omitempty"`
+	Stderr    string        `xml:"system-err,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML.
+func (r *TestResult) WriteJUnit(w io.Writer) error {
+	report := junitTestSuites{Time: formatSeconds(r.Duration)}
+	for _, suite := range r.Suites {
+		s := junitTestSuite{
+			Name:      suite.Name,
+			ID:        suite.ID,
+			Time:      formatSeconds(suite.Duration),
+			Timestamp: suite.Timestamp,
+			Stdout:    suite.Stdout,
+			Stderr:    suite.Stderr,
+		}
+		for i := range suite.Cases {
+			c := &suite.Cases[i]
+			tc := junitTestCase{
+				ClassName: c.ClassName,
+				Name:      c.Name,
+				Time:      formatSeconds(c.Duration),
+				Stdout:    c.Stdout,
+				Stderr:    c.Stderr,
+			}
+			switch {
+			case c.IsFailed():
+				tc.Failure = &junitMessage{Message: c.ErrorDetails, Text: c.ErrorStackTrace}
+				s.Failures++
+			case c.Skipped || c.Status == RESULT_STATUS_SKIPPED:
+				tc.Skipped = &junitMessage{Message: c.SkippedMessage}
+				s.Skipped++
+			}
+			s.Cases = append(s.Cases, tc)
+		}
+		s.Tests = len(s.Cases)
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Skipped += s.Skipped
+		report.Suites = append(report.Suites, s)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64)
+}
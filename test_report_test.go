@@ -0,0 +1,48 @@
+package gojenkins
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestReport(t *testing.T) {
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"duration":1.5,"failCount":2,"passCount":1,"skipCount":1,"suites":[{"name":"pkg.FooTest","duration":1.5,"timestamp":"2020-01-01T00:00:00","id":null,"cases":[
+			{"className":"pkg.FooTest","name":"passes","duration":0.5,"status":"PASSED"},
+			{"className":"pkg.FooTest","name":"broke","duration":0.25,"status":"REGRESSION","age":1,"failedSince":7,"errorDetails":"expected <1>","errorStackTrace":"at Foo.java:10"},
+			{"className":"pkg.FooTest","name":"flaky","duration":0.75,"status":"FAILED","age":3,"failedSince":5,"stdout":"retrying"},
+			{"className":"pkg.FooTest","name":"ignored","status":"SKIPPED","skipped":true,"skippedMessage":"@Ignore"}]}]}`))
+	})
+	defer done()
+	build := &Build{Jenkins: &Jenkins{Requester: r}, Raw: new(BuildResponse), Base: "/job/a/7"}
+
+	report, err := build.GetResultSet()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(report.Failures()))
+	assert.Equal(t, "pkg.FooTest.broke", report.Regressions()[0].GetFullName())
+	assert.Equal(t, "flaky", report.FailingFor(3)[0].Name)
+	assert.Equal(t, "", report.Suites[0].ID)
+
+	var out bytes.Buffer
+	assert.Nil(t, report.WriteJUnit(&out))
+	assert.Equal(t, xml.Header+`<testsuites tests="4" failures="2" skipped="1" time="1.500">
+  <testsuite name="pkg.FooTest" tests="4" failures="2" skipped="1" time="1.500" timestamp="2020-01-01T00:00:00">
+    <testcase classname="pkg.FooTest" name="passes" time="0.500"></testcase>
+    <testcase classname="pkg.FooTest" name="broke" time="0.250">
+      <failure message="expected &lt;1&gt;">at Foo.java:10</failure>
+    </testcase>
+    <testcase classname="pkg.FooTest" name="flaky" time="0.750">
+      <failure></failure>
+      <system-out>retrying</system-out>
+    </testcase>
+    <testcase classname="pkg.FooTest" name="ignored" time="0.000">
+      <skipped message="@Ignore"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, out.String())
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// TestStabilityReport is the outcome of Job.AnalyzeTestStability.
+type TestStabilityReport struct {
+	Job string `json:"job"`
+	// Builds analysed, oldest first.
+	Builds []int64 `json:"builds"`
+	// Builds in the window without a test report.
+	Missing []int64 `json:"missing,omitempty"`
+	// Tests that failed at least once, flakiest first.
+	Tests []*TestStability `json:"tests"`
+}
+
+// TestStability is how a test fared over the analysed builds.
+type TestStability struct {
+	Name string `json:"name"`
+	// Builds the test ran in, skipped runs are not counted.
+	Runs     int `json:"runs"`
+	Failures int `json:"failures"`
+	// How often the test went from passing to failing or back, between
+	// consecutive runs.
+	Flips       int     `json:"flips"`
+	FailureRate float64 `json:"failureRate"`
+	// The first and last builds the test failed in.
+	FirstFailure int64 `json:"firstFailure"`
+	LastFailure  int64 `json:"lastFailure"`
+
+	failing bool
+}
+
+// IsFlaky reports whether the test failed and passed again more than once.
+// A test that broke and stayed broken, or was fixed once, flipped only once.
+func (s *TestStability) IsFlaky() bool {
+	return s.Flips > 1
+}
+
+// Flaky returns the flaky tests of the report.
+func (r *TestStabilityReport) Flaky() []*TestStability {
+	var flaky []*TestStability
+	for _, test := range r.Tests {
+		if test.IsFlaky() {
+			flaky = append(flaky, test)
+		}
+	}
+	return flaky
+}
+
+// Only the case fields needed for the analysis.
+var stabilityTree = Fields().Nested("suites", Fields().Nested("cases", Fields("className", "name", "status")))
+
+// AnalyzeTestStability fetches the test reports of the last lastN builds and
+// reports how often each test failed, and how often it flipped between
+// passing and failing. Tests that never failed are left out.
+func (j *Job) AnalyzeTestStability(lastN int) (*TestStabilityReport, error) {
+	return j.AnalyzeTestStabilityContext(context.Background(), lastN)
+}
+
+func (j *Job) AnalyzeTestStabilityContext(ctx context.Context, lastN int) (*TestStabilityReport, error) {
+	// Only the numbers of the builds analyzed, not the whole history.
+	tree := Fields().Nested("allBuilds", Fields("number", "url"))
+	if lastN > 0 {
+		tree = tree.Range(0, lastN)
+	}
+	var buildsResp struct {
+		Builds []JobBuild `json:"allBuilds"`
+	}
+	if _, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, &buildsResp, map[string]string{"tree": tree.String()}); err != nil {
+		return nil, err
+	}
+	builds := buildsResp.Builds
+
+	report := &TestStabilityReport{Job: j.GetFullName(), Tests: []*TestStability{}}
+	tests := make(map[string]*TestStability)
+	// Builds are listed newest first.
+	for i := len(builds) - 1; i >= 0; i-- {
+		number := builds[i].Number
+		build := &Build{Jenkins: j.Jenkins, Job: j, Raw: new(BuildResponse), Depth: 1, Base: j.Base + "/" + strconv.FormatInt(number, 10)}
+		result, err := build.GetResultSetContext(ctx, stabilityTree)
+		if errors.Is(err, ErrNotFound) {
+			report.Missing = append(report.Missing, number)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Builds = append(report.Builds, number)
+		result.Cases(func(_ *TestSuite, c *TestCase) bool {
+			if c.Status == RESULT_STATUS_SKIPPED {
+				return true
+			}
+			name := c.GetFullName()
+			test := tests[name]
+			if test == nil {
+				test = &TestStability{Name: name}
+				tests[name] = test
+			} else if test.failing != c.IsFailed() {
+				test.Flips++
+			}
+			test.Runs++
+			test.failing = c.IsFailed()
+			if test.failing {
+				test.Failures++
+				if test.FirstFailure == 0 {
+					test.FirstFailure = number
+				}
+				test.LastFailure = number
+			}
+			return true
+		})
+	}
+
+	for _, test := range tests {
+		if test.Failures == 0 {
+			continue
+		}
+		test.FailureRate = float64(test.Failures) / float64(test.Runs)
+		report.Tests = append(report.Tests, test)
+	}
+	sort.Slice(report.Tests, func(a, b int) bool {
+		ta, tb := report.Tests[a], report.Tests[b]
+		if ta.Flips != tb.Flips {
+			return ta.Flips > tb.Flips
+		}
+		if ta.FailureRate != tb.FailureRate {
+			return ta.FailureRate > tb.FailureRate
+		}
+		return ta.Name < tb.Name
+	})
+	return report, nil
+}
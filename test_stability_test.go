@@ -0,0 +1,62 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeTestStability(t *testing.T) {
+	reports := map[string]string{
+		"1": `PASSED`, "2": `FAILED`, "3": `PASSED`, "5": `FAILED`,
+	}
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/job/app/api/json" {
+			assert.Equal(t, "allBuilds[number,url]{0,5}", req.URL.Query().Get("tree"))
+			w.Write([]byte(`{"allBuilds":[{"number":6},{"number":5},{"number":4},{"number":3},{"number":2}]}`))
+			return
+		}
+		number := strings.Split(req.URL.Path, "/")[3]
+		status, ok := reports[number]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		assert.Equal(t, "suites[cases[className,name,status]]", req.URL.Query().Get("tree"))
+		broken := "PASSED"
+		if number >= "3" {
+			broken = "FAILED"
+		}
+		w.Write([]byte(`{"suites":[{"cases":[
+			{"className":"a.B","name":"flaky","status":"` + status + `"},
+			{"className":"a.B","name":"broken","status":"` + broken + `"},
+			{"className":"a.B","name":"stable","status":"PASSED"}]}]}`))
+	})
+	defer done()
+	job := &Job{Jenkins: &Jenkins{Requester: r}, Raw: new(JobResponse), Base: "/job/app"}
+
+	report, err := job.AnalyzeTestStability(5)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{2, 3, 5}, report.Builds)
+	assert.Equal(t, []int64{4, 6}, report.Missing)
+	assert.Equal(t, 2, len(report.Tests))
+
+	flaky := report.Tests[0]
+	assert.Equal(t, "a.B.flaky", flaky.Name)
+	assert.Equal(t, 2, flaky.Flips)
+	assert.Equal(t, int64(2), flaky.FirstFailure)
+	assert.Equal(t, int64(5), flaky.LastFailure)
+	assert.InDelta(t, 2.0/3, flaky.FailureRate, 0.001)
+	assert.Equal(t, []*TestStability{flaky}, report.Flaky())
+
+	broken := report.Tests[1]
+	assert.Equal(t, 1, broken.Flips)
+	assert.Equal(t, int64(3), broken.FirstFailure)
+
+	data, err := json.Marshal(report)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"name":"a.B.broken","runs":3,"failures":2,"flips":1`)
+}
@@ -0,0 +1,215 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Tree selects the fields Jenkins returns from api/json, so that a Poll does
+// not download every build and action of a job. It renders to the value of
+// the tree query parameter:
+//
+//	tree := gojenkins.Fields("name", "color").
+//		Nested("builds", gojenkins.Fields("number", "result")).Range(0, 10)
+//	job.Poll(tree) // ?tree=name,color,builds[number,result]{0,10}
+//
+// Pass a Tree to Poll or to the Get methods that accept options. Fields that
+// are not selected are left as they were.
+type Tree struct {
+	fields []treeField
+}
+
+type treeField struct {
+	name     string
+	sub      *Tree
+	from, to int // -1 if open
+}
+
+// Fields starts a tree with the given leaf fields.
+func Fields(names ...string) *Tree {
+	t := &Tree{}
+	for _, name := range names {
+		t.Field(name)
+	}
+	return t
+}
+
+// Field adds a leaf field.
+func (t *Tree) Field(name string) *Tree {
+	t.fields = append(t.fields, treeField{name: name, from: -1, to: -1})
+	return t
+}
+
+// Nested adds a field together with the fields selected from its value.
+func (t *Tree) Nested(name string, sub *Tree) *Tree {
+	t.fields = append(t.fields, treeField{name: name, sub: sub, from: -1, to: -1})
+	return t
+}
+
+// Range limits the field added last to the elements from (inclusive) to to
+// (exclusive), i.e. {m,n}. A negative bound leaves that side open, so
+// Range(-1, 10) returns the first ten elements.
+func (t *Tree) Range(from, to int) *Tree {
+	if len(t.fields) > 0 {
+		f := &t.fields[len(t.fields)-1]
+		f.from, f.to = from, to
+	}
+	return t
+}
+
+// String renders the tree expression.
+func (t *Tree) String() string {
+	var b strings.Builder
+	t.write(&b)
+	return b.String()
+}
+
+func (t *Tree) write(b *strings.Builder) {
+	for i, f := range t.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(f.name)
+		if f.sub != nil && len(f.sub.fields) > 0 {
+			b.WriteByte('[')
+			f.sub.write(b)
+			b.WriteByte(']')
+		}
+		if f.from >= 0 || f.to >= 0 {
+			b.WriteByte('{')
+			if f.from >= 0 {
+				b.WriteString(strconv.Itoa(f.from))
+			}
+			b.WriteByte(',')
+			if f.to >= 0 {
+				b.WriteString(strconv.Itoa(f.to))
+			}
+			b.WriteByte('}')
+		}
+	}
+}
+
+// TreeOf builds the minimal tree needed to fill the struct v points to, from
+// its json tags. Untagged fields are selected by their Go name with the
+// leading capitals lower-cased, e.g. URL as url. Fields of map or interface
+// type are selected without sub-fields, extend the tree with Nested if you
+// need their contents.
+//
+//	var status struct {
+//		Color     string `json:"color"`
+//		LastBuild struct {
+//			Number int64  `json:"number"`
+//			Result string `json:"result"`
+//		} `json:"lastBuild"`
+//	}
+//	jenkins.Requester.GetJSON(job.Base, &status, map[string]string{"tree": gojenkins.TreeOf(&status).String()})
+func TreeOf(v interface{}) *Tree {
+	return treeOfType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func treeOfType(typ reflect.Type, seen map[reflect.Type]bool) *Tree {
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct || seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	t := &Tree{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			// Embedded struct without a tag, its fields are promoted.
+			if sub := treeOfType(field.Type, seen); sub != nil {
+				t.fields = append(t.fields, sub.fields...)
+			}
+			continue
+		}
+		t.Nested(name, treeOfType(field.Type, seen))
+	}
+	return t
+}
+
+// The key encoding/json uses for a field, or "" for an untagged embedded
+// struct. ok is false for fields that are never decoded.
+func jsonFieldName(field reflect.StructField) (name string, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag != "" {
+		return tag, true
+	}
+	if field.Anonymous {
+		return "", true
+	}
+	if field.PkgPath != "" {
+		return "", false
+	}
+	return lowerInitialism(field.Name), true
+}
+
+// Number -> number, URL -> url, URLPath -> urlPath.
+func lowerInitialism(s string) string {
+	runes := []rune(s)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// The query for the optional arguments of Poll and the Get methods: a *Tree
+// selects fields, an int sets the depth.
+func pollQuery(options []interface{}) map[string]string {
+	var qr map[string]string
+	for _, o := range options {
+		switch v := o.(type) {
+		case *Tree:
+			if v == nil {
+				continue
+			}
+			if qr == nil {
+				qr = map[string]string{}
+			}
+			qr["tree"] = v.String()
+		case int:
+			if qr == nil {
+				qr = map[string]string{}
+			}
+			qr["depth"] = strconv.Itoa(v)
+		}
+	}
+	return qr
+}
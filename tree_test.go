@@ -0,0 +1,43 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	tree := Fields("name", "color").Nested("builds", Fields("number", "result")).Range(0, 10)
+	assert.Equal(t, "name,color,builds[number,result]{0,10}", tree.String())
+	assert.Equal(t, "jobs[name]{,5}", Fields().Nested("jobs", Fields("name")).Range(-1, 5).String())
+
+	var status struct {
+		Color     string `json:"color"`
+		LastBuild *struct {
+			Number int64
+			URL    string
+		} `json:"lastBuild"`
+		Ignored string `json:"-"`
+	}
+	assert.Equal(t, "color,lastBuild[number,url]", TreeOf(&status).String())
+
+	var query url.Values
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		query = req.URL.Query()
+		w.Write([]byte(`{"name":"app"}`))
+	})
+	defer done()
+	job := &Job{Jenkins: &Jenkins{Requester: r}, Raw: new(JobResponse), Base: "/job/app"}
+	_, err := job.Poll(Fields("name"))
+	assert.Nil(t, err)
+	assert.Equal(t, "name", query.Get("tree"))
+	assert.Equal(t, "app", job.GetName())
+
+	plugins := &Plugins{Jenkins: job.Jenkins, Raw: new(PluginResponse), Base: "/pluginManager", Depth: 1}
+	_, err = plugins.Poll(Fields().Nested("plugins", Fields("shortName")))
+	assert.Nil(t, err)
+	assert.Equal(t, "plugins[shortName]", query.Get("tree"))
+	assert.Equal(t, "1", query.Get("depth"))
+}
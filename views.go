@@ -14,10 +14,7 @@
 
 package gojenkins
 
-import (
-	"errors"
-	"strconv"
-)
+import "context"
 
 type View struct {
 	Raw     *ViewResponse
@@ -43,30 +40,38 @@ var (
 
 // Returns True if successfully added Job, otherwise false
 func (v *View) AddJob(name string) (bool, error) {
+	return v.AddJobContext(context.Background(), name)
+}
+
+func (v *View) AddJobContext(ctx context.Context, name string) (bool, error) {
 	url := "/addJobToView"
 	qr := map[string]string{"name": name}
-	resp, err := v.Jenkins.Requester.Post(v.Base+url, nil, nil, qr)
+	resp, err := v.Jenkins.Requester.PostContext(ctx, v.Base+url, nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
 	if resp.StatusCode == 200 {
 		return true, nil
 	}
-	return false, errors.New(strconv.Itoa(resp.StatusCode))
+	return false, newAPIError(resp)
 }
 
 // Returns True if successfully deleted Job, otherwise false
 func (v *View) DeleteJob(name string) (bool, error) {
+	return v.DeleteJobContext(context.Background(), name)
+}
+
+func (v *View) DeleteJobContext(ctx context.Context, name string) (bool, error) {
 	url := "/removeJobFromView"
 	qr := map[string]string{"name": name}
-	resp, err := v.Jenkins.Requester.Post(v.Base+url, nil, nil, qr)
+	resp, err := v.Jenkins.Requester.PostContext(ctx, v.Base+url, nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
 	if resp.StatusCode == 200 {
 		return true, nil
 	}
-	return false, errors.New(strconv.Itoa(resp.StatusCode))
+	return false, newAPIError(resp)
 }
 
 func (v *View) GetDescription() string {
@@ -85,8 +90,14 @@ func (v *View) GetUrl() string {
 	return v.Raw.URL
 }
 
-func (v *View) Poll() (int, error) {
-	response, err := v.Jenkins.Requester.GetJSON(v.Base, v.Raw, nil)
+// Poll for current data. Optional parameters - a *Tree selecting the fields
+// to fetch, or the depth as int.
+func (v *View) Poll(options ...interface{}) (int, error) {
+	return v.PollContext(context.Background(), options...)
+}
+
+func (v *View) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := v.Jenkins.Requester.GetJSONContext(ctx, v.Base, v.Raw, pollQuery(options))
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,132 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const defaultBuildPollInterval = 2 * time.Second
+
+// WaitOptions controls Build.Wait. The zero value polls every two seconds.
+type WaitOptions struct {
+	// Time between the first polls.
+	PollInterval time.Duration
+	// If larger than PollInterval, the interval doubles after every poll up
+	// to MaxPollInterval, for long builds.
+	MaxPollInterval time.Duration
+	// Give up after this long, 0 means wait as long as ctx allows.
+	Timeout time.Duration
+	// Called after every poll while the build is running.
+	Progress func(p WaitProgress)
+}
+
+// WaitProgress reports how far a running build has come.
+type WaitProgress struct {
+	Build     *Build
+	Elapsed   time.Duration
+	Estimated time.Duration // 0 if Jenkins has no estimate yet.
+}
+
+// Fraction returns Elapsed relative to Estimated, e.g. 0.5 halfway through.
+// It can exceed 1 for builds that take longer than usual, and is 0 without
+// an estimate.
+func (p WaitProgress) Fraction() float64 {
+	if p.Estimated <= 0 {
+		return 0
+	}
+	return float64(p.Elapsed) / float64(p.Estimated)
+}
+
+// WaitResult is the outcome of a finished build.
+type WaitResult struct {
+	Build    *Build
+	Result   string // e.g. STATUS_SUCCESS, RESULT_STATUS_FAILURE, STATUS_ABORTED or "UNSTABLE"
+	Duration time.Duration
+}
+
+func (r *WaitResult) IsSuccess() bool {
+	return r.Result == STATUS_SUCCESS
+}
+
+// Fields polled while waiting, instead of the whole build.
+var waitTree = Fields("number", "building", "result", "timestamp", "duration", "estimatedDuration")
+
+// Wait polls the build until it has finished and returns its result. Unlike
+// IsRunning, failed polls are returned as errors, as is the error of ctx if
+// it is cancelled or opts.Timeout expires first. opts may be nil.
+func (b *Build) Wait(ctx context.Context, opts *WaitOptions) (*WaitResult, error) {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	ctx = WithoutCache(ctx)
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultBuildPollInterval
+	}
+
+	for {
+		if _, err := b.PollContext(ctx, waitTree); err != nil {
+			return nil, b.waitError(ctx, err)
+		}
+		if !b.Raw.Building {
+			break
+		}
+		if opts.Progress != nil {
+			elapsed := time.Since(b.GetTimestamp())
+			if elapsed < 0 {
+				elapsed = 0
+			}
+			opts.Progress(WaitProgress{
+				Build:     b,
+				Elapsed:   elapsed,
+				Estimated: time.Duration(b.Raw.EstimatedDuration) * time.Millisecond,
+			})
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, b.waitError(ctx, err)
+		}
+		if opts.MaxPollInterval > interval {
+			interval *= 2
+			if interval > opts.MaxPollInterval {
+				interval = opts.MaxPollInterval
+			}
+		}
+	}
+
+	// Fetch the rest of the finished build, e.g. its artifacts.
+	if _, err := b.PollContext(ctx); err != nil {
+		return nil, b.waitError(ctx, err)
+	}
+	return &WaitResult{
+		Build:    b,
+		Result:   b.Raw.Result,
+		Duration: time.Duration(b.Raw.Duration) * time.Millisecond,
+	}, nil
+}
+
+func (b *Build) waitError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return fmt.Errorf("jenkins: waiting for build %s: %w", b.Base, err)
+}
@@ -0,0 +1,69 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildWait(t *testing.T) {
+	polls := 0
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		polls++
+		start := strconv.FormatInt(time.Now().Add(-time.Minute).UnixNano()/int64(time.Millisecond), 10)
+		if polls < 3 {
+			assert.NotEmpty(t, req.URL.Query().Get("tree"))
+			w.Write([]byte(`{"number":7,"building":true,"timestamp":` + start + `,"estimatedDuration":120000}`))
+			return
+		}
+		w.Write([]byte(`{"number":7,"building":false,"result":"UNSTABLE","duration":61000}`))
+	})
+	defer done()
+	build := &Build{Jenkins: &Jenkins{Requester: r}, Raw: new(BuildResponse), Base: "/job/a/7"}
+
+	var progress []WaitProgress
+	result, err := build.Wait(context.Background(), &WaitOptions{
+		PollInterval: time.Millisecond,
+		Progress:     func(p WaitProgress) { progress = append(progress, p) },
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "UNSTABLE", result.Result)
+	assert.False(t, result.IsSuccess())
+	assert.Equal(t, 61*time.Second, result.Duration)
+	assert.Equal(t, 2, len(progress))
+	assert.InDelta(t, 0.5, progress[0].Fraction(), 0.05)
+
+	polls = 0
+	_, err = build.Wait(context.Background(), &WaitOptions{PollInterval: time.Second, Timeout: 10 * time.Millisecond})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestBuildWaitWithCache(t *testing.T) {
+	polls := 0
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		polls++
+		if polls < 3 {
+			w.Write([]byte(`{"number":7,"building":true}`))
+			return
+		}
+		w.Write([]byte(`{"number":7,"building":false,"result":"SUCCESS"}`))
+	})
+	defer done()
+	r.Cache = NewCache(NewMemoryStore(100), time.Hour)
+	build := &Build{Jenkins: &Jenkins{Requester: r}, Raw: new(BuildResponse), Base: "/job/a/7"}
+
+	result, err := build.Wait(context.Background(), &WaitOptions{PollInterval: time.Millisecond, Timeout: 5 * time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, "SUCCESS", result.Result)
+
+	// Other requests are still served from the cache.
+	seen := polls
+	_, err = build.Poll()
+	assert.Nil(t, err)
+	assert.Equal(t, seen, polls)
+}
@@ -0,0 +1,248 @@
+// Copyright 2015 Vadim Kravcenko
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package gojenkins
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Number of folder levels fetched with a single request. Deeper folders are
+// fetched with another request each.
+const walkLevelsPerRequest = 4
+
+// JobEntry is a job or folder found by WalkJobs.
+type JobEntry struct {
+	FullName string
+	Name     string
+	Class    string // e.g. "org.jenkinsci.plugins.workflow.job.WorkflowJob"
+	URL      string
+	Color    string
+	Depth    int  // 1 for items directly below the walk's root
+	Folder   bool // Folders, organization folders and multibranch projects
+	Job      *Job // Set if WalkOptions.Hydrate is true
+}
+
+// WalkOptions controls which items WalkJobs reports. The zero value reports
+// every job and folder of the instance.
+type WalkOptions struct {
+	// Full name of the folder to start from, all of Jenkins if empty.
+	Root string
+	// Do not descend more than MaxDepth levels, 0 means no limit.
+	MaxDepth int
+	// Only report items of these classes. Both the full class name and the
+	// part after the last dot are accepted, e.g. "WorkflowJob".
+	Classes []string
+	// Only report items whose full name matches this pattern, see path.Match.
+	Match string
+	// Fetch the full Job for every reported item, in batches of Concurrency
+	// items fetched in parallel, 4 by default.
+	Hydrate     bool
+	Concurrency int
+}
+
+// Called for every reported item, in depth-first order with folders before
+// their contents. Returning an error stops the walk and WalkJobs returns it.
+type WalkFunc func(entry *JobEntry) error
+
+type walkItem struct {
+	Class    string     `json:"_class"`
+	Name     string     `json:"name"`
+	FullName string     `json:"fullName"`
+	URL      string     `json:"url"`
+	Color    string     `json:"color"`
+	Jobs     []walkItem `json:"jobs"`
+}
+
+// The tree for levels of items, plus the names of the children of the last
+// level, so that we know whether there is more to fetch.
+func walkTree(levels int) *Tree {
+	item := Fields("_class", "name", "fullName", "url", "color")
+	if levels <= 1 {
+		return item.Nested("jobs", Fields("name"))
+	}
+	return item.Nested("jobs", walkTree(levels-1))
+}
+
+// WalkJobs visits all jobs below opts.Root, recursing into folders,
+// organization folders and multibranch projects. fn is called as the items
+// are fetched, a folder page at a time, so an error from fn stops the walk
+// before the next request.
+func (j *Jenkins) WalkJobs(fn WalkFunc, opts *WalkOptions) error {
+	return j.WalkJobsContext(context.Background(), fn, opts)
+}
+
+func (j *Jenkins) WalkJobsContext(ctx context.Context, fn WalkFunc, opts *WalkOptions) error {
+	if opts == nil {
+		opts = &WalkOptions{}
+	}
+	w := &walker{jenkins: j, opts: opts, fn: fn}
+	if err := w.walk(ctx, strings.Trim(opts.Root, "/"), 0); err != nil {
+		return err
+	}
+	return w.flush(ctx)
+}
+
+type walker struct {
+	jenkins *Jenkins
+	opts    *WalkOptions
+	fn      WalkFunc
+	// Entries to report once they are hydrated.
+	pending []*JobEntry
+}
+
+// Fetch the items below the folder root, which is depth levels below the
+// root of the walk, and report the ones that match.
+func (w *walker) walk(ctx context.Context, root string, depth int) error {
+	// Report everything found so far first, fn may stop the walk.
+	if err := w.flush(ctx); err != nil {
+		return err
+	}
+	levels := walkLevelsPerRequest
+	if w.opts.MaxDepth > 0 && w.opts.MaxDepth-depth < levels {
+		levels = w.opts.MaxDepth - depth
+	}
+	endpoint := "/"
+	if root != "" {
+		endpoint = JobPath(root)
+	}
+	folder := new(walkItem)
+	tree := Fields().Nested("jobs", walkTree(levels))
+	if _, err := w.jenkins.Requester.GetJSONContext(ctx, endpoint, folder, map[string]string{"tree": tree.String()}); err != nil {
+		return err
+	}
+	return w.walkItems(ctx, root, folder.Jobs, depth+1, levels)
+}
+
+func (w *walker) walkItems(ctx context.Context, parent string, items []walkItem, depth int, levels int) error {
+	for _, item := range items {
+		entry := &JobEntry{
+			FullName: item.FullName,
+			Name:     item.Name,
+			Class:    item.Class,
+			URL:      item.URL,
+			Color:    item.Color,
+			Depth:    depth,
+			Folder:   item.Jobs != nil,
+		}
+		if entry.FullName == "" {
+			entry.FullName = path.Join(parent, item.Name)
+		}
+		if w.opts.matches(entry) {
+			if err := w.report(ctx, entry); err != nil {
+				return err
+			}
+		}
+		if len(item.Jobs) == 0 || (w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth) {
+			continue
+		}
+		var err error
+		if levels > 1 {
+			err = w.walkItems(ctx, entry.FullName, item.Jobs, depth+1, levels-1)
+		} else {
+			err = w.walk(ctx, entry.FullName, depth)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pass entry to fn, or queue it until a batch of entries can be hydrated.
+func (w *walker) report(ctx context.Context, entry *JobEntry) error {
+	if !w.opts.Hydrate {
+		return w.fn(entry)
+	}
+	w.pending = append(w.pending, entry)
+	if len(w.pending) < w.opts.concurrency() {
+		return nil
+	}
+	return w.flush(ctx)
+}
+
+// Hydrate and report the queued entries.
+func (w *walker) flush(ctx context.Context) error {
+	entries := w.pending
+	w.pending = nil
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := w.jenkins.hydrate(ctx, entries); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := w.fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (opts *WalkOptions) concurrency() int {
+	if opts.Concurrency <= 0 {
+		return 4
+	}
+	return opts.Concurrency
+}
+
+func (opts *WalkOptions) matches(entry *JobEntry) bool {
+	if opts.Match != "" {
+		if ok, _ := path.Match(opts.Match, entry.FullName); !ok {
+			return false
+		}
+	}
+	if len(opts.Classes) == 0 {
+		return true
+	}
+	short := entry.Class[strings.LastIndex(entry.Class, ".")+1:]
+	for _, class := range opts.Classes {
+		if class == entry.Class || class == short {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch the full Job of every entry, all at the same time.
+func (j *Jenkins) hydrate(ctx context.Context, entries []*JobEntry) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry *JobEntry) {
+			defer wg.Done()
+			job, err := j.GetJobContext(ctx, entry.FullName)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			entry.Job = job
+		}(entry)
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,81 @@
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkJobs(t *testing.T) {
+	const folder, pipeline = "com.cloudbees.hudson.plugins.folder.Folder", "org.jenkinsci.plugins.workflow.job.WorkflowJob"
+	var requests []string
+	r, done := newTestRequester(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.Path)
+		switch req.URL.Path {
+		case "/api/json":
+			w.Write([]byte(`{"jobs":[
+				{"_class":"` + pipeline + `","name":"deploy","color":"blue"},
+				{"_class":"` + folder + `","name":"a","jobs":[
+					{"_class":"` + folder + `","name":"b","jobs":[
+						{"_class":"` + folder + `","name":"c","jobs":[
+							{"_class":"` + folder + `","name":"d","jobs":[{"name":"e"}]}]}]}]}]}`))
+		case "/job/a/job/b/job/c/job/d/api/json":
+			w.Write([]byte(`{"jobs":[{"_class":"` + pipeline + `","name":"e","fullName":"a/b/c/d/e","color":"red"}]}`))
+		case "/job/deploy/api/json", "/job/a/job/b/job/c/job/d/job/e/api/json":
+			w.Write([]byte(`{"name":"hydrated"}`))
+		}
+	})
+	defer done()
+	jenkins := &Jenkins{Server: r.Base, Requester: r}
+
+	var names []string
+	err := jenkins.WalkJobs(func(entry *JobEntry) error {
+		names = append(names, entry.FullName+":"+strconv.Itoa(entry.Depth))
+		return nil
+	}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"deploy:1", "a:1", "a/b:2", "a/b/c:3", "a/b/c/d:4", "a/b/c/d/e:5"}, names)
+	assert.Equal(t, []string{"/api/json", "/job/a/job/b/job/c/job/d/api/json"}, requests)
+
+	var jobs []*JobEntry
+	err = jenkins.WalkJobs(func(entry *JobEntry) error {
+		jobs = append(jobs, entry)
+		return nil
+	}, &WalkOptions{Classes: []string{"WorkflowJob"}, Hydrate: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(jobs))
+	for _, entry := range jobs {
+		assert.False(t, entry.Folder)
+		assert.Equal(t, "hydrated", entry.Job.GetName())
+	}
+
+	names = nil
+	err = jenkins.WalkJobs(func(entry *JobEntry) error {
+		names = append(names, entry.FullName)
+		return nil
+	}, &WalkOptions{MaxDepth: 2, Match: "a/*"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a/b"}, names)
+
+	// An error from fn stops the walk before the next request.
+	stop := errors.New("stop")
+	requests = nil
+	err = jenkins.WalkJobs(func(entry *JobEntry) error {
+		if entry.FullName == "a/b" {
+			return stop
+		}
+		return nil
+	}, nil)
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"/api/json"}, requests)
+
+	requests = nil
+	err = jenkins.WalkJobs(func(entry *JobEntry) error {
+		return stop
+	}, &WalkOptions{Classes: []string{"WorkflowJob"}, Hydrate: true, Concurrency: 1})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"/api/json", "/job/deploy/api/json"}, requests)
+}